@@ -0,0 +1,103 @@
+package apod
+
+import (
+	"fmt"
+	"time"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// Source implements source.Source for NASA Astronomy Picture of the
+// Day.
+type Source struct {
+	API string
+	Key string
+}
+
+// Date returns the date of APOD that should be fetched for q. If
+// random is true then a random date is returned, if date was not
+// passed then today's date is returned.
+func (s *Source) Date(q source.Query) (string, error) {
+	if q.Random {
+		return RandDate(), nil
+	}
+	if len(q.Date) != 0 {
+		return q.Date, nil
+	}
+
+	date := time.Now().UTC().
+		// Subtract 8 hours from UTC to ensure program doesn't
+		// fail. This still doesn't mean anything, I've emailed
+		// them asking about timezone on server but no response
+		// :(
+		//
+		// The server returns "400 Bad Request" when you
+		// request future date, so if I request 2020-04-25 on
+		// 2020-04-24 23:59 UTC it will return "400 Bad
+		// Request" but if I re-request it on 2020-04-25 00:04
+		// UTC it returns "500 Internal Server Error", I think
+		// the API server runs on UTC but the program that is
+		// responsible for syncing the images is running on a
+		// different timezone, which is why it returns "500
+		// Internal Server Error" instead of "400 Bad Request".
+		//
+		// Hopefully this should work, it will work if the
+		// program responsible for syncing images is in or
+		// before UTC-8.
+		Add(time.Duration(-8) * time.Hour).
+		Format("2006-01-02")
+	return date, nil
+}
+
+// Fetch returns the response body for APOD of q.Date.
+func (s *Source) Fetch(q source.Query) (string, error) {
+	// reqInfo holds all the parameters that needs to be sent with
+	// the request. GetJson() will pack apiKey & date in params
+	// map before sending it to another function. Adding params
+	// here will not change the behaviour of the function, changes
+	// have to be made in GetJson() too.
+	reqInfo := make(map[string]string)
+	reqInfo["api"] = s.API
+	reqInfo["apiKey"] = s.Key
+	reqInfo["date"] = q.Date
+
+	body, err := GetJson(reqInfo)
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"source.go: failed to get json response from api",
+			err.Error())
+	}
+	return body, err
+}
+
+// Parse converts body to source.Picture. It returns an error if the api
+// returned an error message instead of APOD.
+func (s *Source) Parse(body string) (source.Picture, error) {
+	pic := source.Picture{}
+
+	res := APOD{}
+	err := UnmarshalJson(&res, body)
+	if err != nil {
+		return pic, err
+	}
+
+	// res.Msg will be returned when there is error on user input
+	// or the api server.
+	if len(res.Msg) != 0 {
+		return pic, fmt.Errorf("Message: %s", res.Msg)
+	}
+
+	pic.Service = "apod"
+	pic.Date = res.Date
+	pic.Title = res.Title
+	pic.Credit = res.Copyright
+	pic.Description = res.Explanation
+	pic.MediaType = res.MediaType
+	pic.URL = res.URL
+	if res.MediaType == "image" {
+		pic.URL = res.HDURL
+	}
+	pic.Body = body
+
+	return pic, nil
+}
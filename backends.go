@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"tildegit.org/andinus/cetus/background"
+)
+
+// execBackends prints every backend known on this platform, whether
+// the program it requires is in $PATH & which backend would be used
+// along with the reason. Backend detected from the environment is also
+// printed if the user forced one.
+func execBackends() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Backend\tCommand\tIn $PATH\tUsed on")
+	for _, b := range background.Backends() {
+		command, found := b.Command, "yes"
+		if len(command) == 0 {
+			command = "-"
+		}
+		if !b.Available {
+			found = "no"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", b.Name, command, found, b.Desc)
+	}
+	w.Flush()
+
+	auto, reason := background.AutoDetect()
+	if len(background.Backend) != 0 && background.Backend != "auto" {
+		fmt.Printf("\nSelected: %s (set by cetus.backend (%s))\n",
+			background.Backend, conf.Value("cetus.backend").Origin)
+		fmt.Printf("Detected: %s (%s)\n", auto, reason)
+		return
+	}
+	fmt.Printf("\nSelected: %s (%s)\n", auto, reason)
+}
@@ -0,0 +1,160 @@
+package background
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+var (
+	// Backend is the program used to set the background. If it's
+	// empty or "auto" then the backend is chosen by SetFromFile
+	// depending on the environment.
+	Backend string
+
+	// GnomeMode is the value of picture-options on GNOME, it
+	// decides how the background is scaled.
+	GnomeMode = "zoom"
+
+	// GnomeLockScreen sets the lock screen background on GNOME
+	// too if true.
+	GnomeLockScreen bool
+)
+
+// backend describes a way of setting the background. command is the
+// program it requires, it's empty if it doesn't require any.
+type backend struct {
+	name    string
+	command string
+	desc    string
+	set     func(path string) error
+}
+
+// Info holds information about a backend, it's returned by Backends.
+type Info struct {
+	Name      string
+	Command   string
+	Desc      string
+	Available bool
+}
+
+// Backends returns every backend known on this platform along with
+// whether the program it requires is in $PATH.
+func Backends() []Info {
+	list := []Info{}
+	for _, b := range backends {
+		i := Info{Name: b.name, Command: b.command, Desc: b.desc,
+			Available: true}
+		if len(b.command) != 0 {
+			_, err := exec.LookPath(b.command)
+			i.Available = err == nil
+		}
+		list = append(list, i)
+	}
+	return list
+}
+
+// Detect returns the backend that SetFromFile will use along with the
+// reason it was chosen.
+func Detect() (string, string) {
+	if len(Backend) != 0 && Backend != "auto" {
+		return Backend, "set by the user"
+	}
+	return detect()
+}
+
+// AutoDetect returns the backend that would be chosen by looking at the
+// environment along with the reason, Backend set by the user is
+// ignored.
+func AutoDetect() (string, string) {
+	return detect()
+}
+
+// SetFromFile takes a string as an input, it must be absolute path to
+// the background. Checks are not made to check if the path exists or
+// it is actually an image, that must be verified before passing it to
+// SetFromFile. SetFromFile will exit returning in error if there is
+// any.
+func SetFromFile(path string) error {
+	name, reason := Detect()
+
+	for _, b := range backends {
+		if b.name != name {
+			continue
+		}
+
+		err := b.set(path)
+		if err != nil {
+			err = fmt.Errorf("background.go: backend %s (%s) failed\n%s",
+				name, reason, err.Error())
+		}
+		return err
+	}
+	return fmt.Errorf("background.go: invalid backend: %q, run `cetus backends` to list them",
+		name)
+}
+
+// Output is a monitor, X & Y is its position in the screen. Index is
+// the position of the output in the order reported by the system,
+// some backends take one file per output in that order.
+type Output struct {
+	Name   string
+	Index  int
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// OutputSupport returns whether the backend that SetFromFile will use
+// can set a different background on every output & whether it can
+// set a single background spanning all of them.
+func OutputSupport() (bool, bool) {
+	name, _ := Detect()
+	_, perOutput := outputSetters[name]
+	_, spanned := spannedSetters[name]
+	return perOutput, spanned
+}
+
+// SetOutputs sets files[i] as the background of outputs[i], files
+// must be absolute paths. It returns an error if the backend can't
+// set a different background on every output.
+func SetOutputs(outputs []Output, files []string) error {
+	name, reason := Detect()
+
+	set, ok := outputSetters[name]
+	if !ok {
+		return fmt.Errorf("background.go: backend %s (%s) can't set a different background on every output",
+			name, reason)
+	}
+	if len(outputs) != len(files) {
+		return fmt.Errorf("background.go: got %d files for %d outputs",
+			len(files), len(outputs))
+	}
+
+	err := set(outputs, files)
+	if err != nil {
+		err = fmt.Errorf("background.go: backend %s (%s) failed\n%s",
+			name, reason, err.Error())
+	}
+	return err
+}
+
+// SetSpanned sets path as a single background spanning all outputs,
+// the image should be the size of the whole screen. It returns an
+// error if the backend can't span the background.
+func SetSpanned(path string) error {
+	name, reason := Detect()
+
+	set, ok := spannedSetters[name]
+	if !ok {
+		return fmt.Errorf("background.go: backend %s (%s) can't span the background across outputs",
+			name, reason)
+	}
+
+	err := set(path)
+	if err != nil {
+		err = fmt.Errorf("background.go: backend %s (%s) failed\n%s",
+			name, reason, err.Error())
+	}
+	return err
+}
@@ -2,47 +2,310 @@ package background
 
 import (
 	"fmt"
+	"image"
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	// Decoders for formats that the image package supports, they
+	// are used to verify downloads.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"tildegit.org/andinus/cetus/request"
 )
 
-// Download takes path and url as input and downloads the data to a
-// file, returning an error if there is one.
-func Download(file string, url string) error {
-	o, err := os.Create(file)
+// exts maps Content-Type of images to the extension used for the
+// cached file. Formats like webp & tiff can't be decoded, they're
+// cached without being verified.
+var exts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/tiff": ".tiff",
+	"image/bmp":  ".bmp",
+}
+
+// formatExts maps format names returned by image.Decode to extensions.
+var formatExts = map[string]string{
+	"jpeg": ".jpg",
+	"png":  ".png",
+	"gif":  ".gif",
+}
+
+// Cached returns the path to the image cached at base with any of the
+// known extensions, it returns an empty string if there is none.
+func Cached(base string) string {
+	for _, ext := range exts {
+		if info, err := os.Stat(base + ext); err == nil && info.Mode().IsRegular() {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// Download takes base path and url as input and downloads the data to
+// base with an extension taken from Content-Type, url or the decoded
+// image in that order. Path to the file is returned along with an
+// error if there is one. Data is written to base.part & it's renamed
+// only after the download is complete & verified to be an image, so
+// the file either doesn't exist or is a complete image. Formats that
+// can't be decoded are accepted unverified if Content-Type or url says
+// that it's an image in such a format. If the
+// download is interrupted then it's resumed from base.part with a
+// range request, this is tried request.Attempts times. Temporary
+// failures of a request are retried by request.Do.
+func Download(base string, url string) (string, error) {
+	part := base + ".part"
+
+	var err error
+	var contentType string
+	for attempt := 1; ; attempt++ {
+		var resumable bool
+		contentType, resumable, err = download(part, url)
+		if err == nil || !resumable || attempt >= request.Attempts {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	format, err := verify(part)
+	if err != nil {
+		os.Remove(part)
+		return "", err
+	}
+
+	ext := exts[strings.TrimSpace(strings.Split(contentType, ";")[0])]
+	if len(ext) == 0 {
+		ext = urlExt(url)
+	}
+	if len(ext) == 0 {
+		ext = formatExts[format]
+	}
+	if len(format) == 0 && !unverifiable(ext) {
+		os.Remove(part)
+		return "", fmt.Errorf("download.go: %s is not a known image format: %q",
+			url, contentType)
+	}
+
+	file := base + ext
+	err = os.Rename(part, file)
 	if err != nil {
 		err = fmt.Errorf("%s%s\n%s",
-			"download.go: failed to create file: ", file,
+			"download.go: failed to rename file: ", part,
 			err.Error())
-		return err
 	}
-	defer o.Close()
+	return file, err
+}
+
+// urlExt returns the extension of file in url if it's a known image
+// extension, otherwise an empty string is returned.
+func urlExt(url string) string {
+	ext := strings.ToLower(path.Ext(strings.Split(url, "?")[0]))
+	switch ext {
+	case ".jpeg":
+		ext = ".jpg"
+	case ".tif":
+		ext = ".tiff"
+	}
+	for _, e := range exts {
+		if e == ext {
+			return ext
+		}
+	}
+	return ""
+}
 
-	res, err := http.Get(url)
+// download downloads url to part, it's resumed if part exists.
+// Content-Type of the response is returned, along with true & the
+// error if the download was interrupted & can be resumed.
+func download(part, url string) (string, bool, error) {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := request.NewRequest(url)
+	if err != nil {
+		return "", false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := request.Do(req)
 	if err != nil {
 		err = fmt.Errorf("%s%s\n%s",
 			"download.go: failed to get response from ", url,
 			err.Error())
-		return err
+		return "", false, err
 	}
 	defer res.Body.Close()
 
-	// Return an error on unexpected response code.
-	if res.StatusCode != http.StatusOK {
-		err = fmt.Errorf("Unexpected Response: %s",
+	flag := os.O_CREATE | os.O_WRONLY
+	switch {
+	case res.StatusCode == http.StatusPartialContent && rangeStart(res) == offset:
+		flag |= os.O_APPEND
+
+	case res.StatusCode == http.StatusPartialContent,
+		res.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Server sent a different range, part is complete or
+		// the file on server changed, either way start again.
+		os.Remove(part)
+		return "", true, fmt.Errorf("download.go: server rejected resuming %s: %s",
+			part, res.Status)
+
+	case res.StatusCode == http.StatusOK:
+		// Server doesn't support range requests, start from
+		// the beginning.
+		flag |= os.O_TRUNC
+		offset = 0
+
+	default:
+		// Return an error on unexpected response code.
+		return "", false, fmt.Errorf("Unexpected Response: %s",
 			res.Status)
-		return err
 	}
 
+	o, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"download.go: failed to create file: ", part,
+			err.Error())
+		return "", false, err
+	}
+	defer o.Close()
+
 	// This will not copy everything to memory but will save to
 	// disk as it progresses, ideal for big files or low memory
 	// environments.
-	_, err = io.Copy(o, res.Body)
+	n, err := io.Copy(o, res.Body)
 	if err != nil {
 		err = fmt.Errorf("%s\n%s",
 			"download.go: failed to copy body to file",
 			err.Error())
+		return "", true, err
+	}
+
+	// ContentLength is -1 if the server didn't send it.
+	if res.ContentLength >= 0 && n != res.ContentLength {
+		return "", true, fmt.Errorf("download.go: got %d bytes, expected %d",
+			n, res.ContentLength)
+	}
+	return res.Header.Get("Content-Type"), false, nil
+}
+
+// rangeStart returns the first byte position in Content-Range header
+// of res, it returns -1 if the header is invalid.
+func rangeStart(res *http.Response) int64 {
+	v := strings.TrimPrefix(res.Header.Get("Content-Range"), "bytes ")
+	i := strings.Index(v, "-")
+	if i < 0 {
+		return -1
+	}
+	start, err := strconv.ParseInt(v[:i], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// Verify returns an error if file is not an image that can be
+// decoded. Files in formats that can't be decoded are not verified,
+// only their extension is checked.
+func Verify(file string) error {
+	format, err := verify(file)
+	if err == nil && len(format) == 0 && !unverifiable(urlExt(file)) {
+		err = fmt.Errorf("download.go: file is not a known image format: %s", file)
+	}
+	return err
+}
+
+// Check is like Verify but only the header of file is decoded, it's
+// used on files that were verified when they were downloaded.
+func Check(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"download.go: failed to open file: ", file,
+			err.Error())
+	}
+	defer f.Close()
+
+	_, _, err = image.DecodeConfig(f)
+	if err == image.ErrFormat && unverifiable(urlExt(file)) {
+		return nil
+	}
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"download.go: file is not a valid image: ", file,
+			err.Error())
 	}
 	return err
 }
+
+// Ext returns the extension for image in file, it's taken from url if
+// the format can't be decoded. An error is returned if file is not a
+// valid image or the extension is not known.
+func Ext(file, url string) (string, error) {
+	format, err := verify(file)
+	if err != nil {
+		return "", err
+	}
+	if len(format) == 0 {
+		if ext := urlExt(url); unverifiable(ext) {
+			return ext, nil
+		}
+		return "", fmt.Errorf("download.go: file is not a known image format: %s", file)
+	}
+	return formatExts[format], nil
+}
+
+// unverifiable returns true if ext is a known image extension whose
+// format can't be decoded.
+func unverifiable(ext string) bool {
+	for _, e := range formatExts {
+		if e == ext {
+			return false
+		}
+	}
+	for _, e := range exts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// verify returns the format of image in file, it returns an error if
+// it can't be decoded. The whole image is decoded so that truncated
+// files are caught even if the server didn't send Content-Length.
+// Formats that Go can't decode can't be verified, an empty format is
+// returned for them without an error.
+func verify(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("%s%s\n%s",
+			"download.go: failed to open file: ", file,
+			err.Error())
+	}
+	defer f.Close()
+
+	_, format, err := image.Decode(f)
+	if err == image.ErrFormat {
+		return "", nil
+	}
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"download.go: file is not a valid image: ", file,
+			err.Error())
+	}
+	return format, err
+}
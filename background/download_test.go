@@ -0,0 +1,117 @@
+package background
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDownload tests if an interrupted download is resumed from the
+// partial file & renamed only after it's complete.
+func TestDownload(t *testing.T) {
+	buf := new(bytes.Buffer)
+	png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	data := buf.Bytes()
+
+	ranges := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil {
+			w.Header().Set("Content-Range",
+				fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		w.Write(data[start:])
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	base := filepath.Join(dir, "img")
+
+	// Half of the image was downloaded earlier.
+	err = ioutil.WriteFile(base+".part", data[:len(data)/2], 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := Download(base, ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != base+".png" {
+		t.Errorf("Got file %q, want %q.", file, base+".png")
+	}
+	if len(ranges) != 1 || ranges[0] != fmt.Sprintf("bytes=%d-", len(data)/2) {
+		t.Errorf("Got range headers %q, want resume from %d.", ranges, len(data)/2)
+	}
+
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Downloaded file differs from the source.")
+	}
+	if _, err := os.Stat(base + ".part"); !os.IsNotExist(err) {
+		t.Errorf("Partial file still exists after download.")
+	}
+}
+
+// TestDownloadUnverifiable tests if formats that can't be decoded are
+// accepted only when Content-Type says so.
+func TestDownloadUnverifiable(t *testing.T) {
+	contentType := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte("RIFF....WEBPVP8 "))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		contentType string
+		ext         string
+	}{
+		{"image/webp", ".webp"},
+		{"text/html", ""},
+		{"image/jpeg", ""},
+	}
+	for i, test := range tests {
+		contentType = test.contentType
+		base := filepath.Join(dir, fmt.Sprintf("img%d", i))
+		file, err := Download(base, ts.URL)
+		if len(test.ext) == 0 {
+			if err == nil {
+				t.Errorf("Download with %s didn't return an error.", test.contentType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Download with %s returned error: %s", test.contentType, err)
+			continue
+		}
+		if file != base+test.ext {
+			t.Errorf("Got file %q, want %q.", file, base+test.ext)
+		}
+		if err := Verify(file); err != nil {
+			t.Errorf("Verify(%q) returned error: %s", file, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gnomeModes holds valid values of picture-options.
+var gnomeModes = map[string]bool{
+	"none":      true,
+	"wallpaper": true,
+	"centered":  true,
+	"scaled":    true,
+	"stretched": true,
+	"zoom":      true,
+	"spanned":   true,
+}
+
+// setGnomeSpanned sets path as a single background spanning all
+// outputs on GNOME, Unity & Pantheon.
+func setGnomeSpanned(path string) error {
+	mode := GnomeMode
+	GnomeMode = "spanned"
+	defer func() { GnomeMode = mode }()
+	return setGnome(path)
+}
+
+// setGnome sets the background on GNOME, Unity & Pantheon with
+// gsettings. Both light & dark uri are set because GNOME 42+ uses
+// picture-uri-dark in dark mode, older versions don't have that key
+// so it's set only if it exists. Keys are read back to verify that
+// the change took effect.
+func setGnome(path string) error {
+	if !gnomeModes[GnomeMode] {
+		return fmt.Errorf("gnome_unix.go: invalid mode: %q", GnomeMode)
+	}
+
+	// gsettings takes path in format of a uri
+	uri := fmt.Sprintf("%s%s", "file://", path)
+
+	schemas := []string{"org.gnome.desktop.background"}
+	if GnomeLockScreen {
+		schemas = append(schemas, "org.gnome.desktop.screensaver")
+	}
+
+	for _, schema := range schemas {
+		keys, err := gsettingsKeys(schema)
+		if err != nil {
+			return err
+		}
+
+		// picture-options is set first so that the new
+		// background is drawn with the right mode.
+		values := [][2]string{
+			{"picture-options", GnomeMode},
+			{"picture-uri", uri},
+		}
+		if keys["picture-uri-dark"] {
+			values = append(values, [2]string{"picture-uri-dark", uri})
+		}
+
+		for _, v := range values {
+			err = gsettingsSet(schema, v[0], v[1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gsettingsKeys returns the keys in schema.
+func gsettingsKeys(schema string) (map[string]bool, error) {
+	keys := make(map[string]bool)
+
+	out, err := exec.Command("gsettings", "list-keys", schema).Output()
+	if err != nil {
+		return keys, fmt.Errorf("%s%s\n%s",
+			"gnome_unix.go: failed to list keys with gsettings: ", schema,
+			err.Error())
+	}
+	for _, k := range strings.Fields(string(out)) {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// gsettingsSet sets key in schema to value & reads it back to verify
+// that it was set.
+func gsettingsSet(schema, key, value string) error {
+	err := exec.Command("gsettings", "set", schema, key, value).Run()
+	if err != nil {
+		return fmt.Errorf("%s%s %s\n%s",
+			"gnome_unix.go: failed to set background with gsettings: ", schema, key,
+			err.Error())
+	}
+
+	out, err := exec.Command("gsettings", "get", schema, key).Output()
+	if err != nil {
+		return fmt.Errorf("%s%s %s\n%s",
+			"gnome_unix.go: failed to read key with gsettings: ", schema, key,
+			err.Error())
+	}
+
+	got := unquoteGVariant(strings.TrimSpace(string(out)))
+	if got != value {
+		return fmt.Errorf("gnome_unix.go: %s %s is %q after setting it to %q",
+			schema, key, got, value)
+	}
+	return nil
+}
+
+// unquoteGVariant unquotes a GVariant string as printed by gsettings,
+// strings are single quoted & quotes inside them are escaped with a
+// backslash. Enums are printed the same way.
+func unquoteGVariant(s string) string {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return s
+	}
+	s = s[1 : len(s)-1]
+
+	r := strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`)
+	return r.Replace(s)
+}
@@ -0,0 +1,11 @@
+// +build darwin
+
+package background
+
+import "fmt"
+
+// Outputs returns an error on macOS because osascript can't set a
+// different background on every output.
+func Outputs() ([]Output, error) {
+	return nil, fmt.Errorf("outputs_darwin.go: listing outputs is not supported on macOS")
+}
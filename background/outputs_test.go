@@ -0,0 +1,61 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import "testing"
+
+func TestParseXrandr(t *testing.T) {
+	out := `Monitors: 2
+ 0: +*eDP-1 1920/344x1080/194+2560+0  eDP-1
+ 1: +HDMI-1 2560/597x1440/336+0+0  HDMI-1
+`
+	outputs := parseXrandr(out)
+	sortOutputs(outputs)
+
+	want := []Output{
+		{Name: "HDMI-1", Index: 1, X: 0, Y: 0, Width: 2560, Height: 1440},
+		{Name: "eDP-1", Index: 0, X: 2560, Y: 0, Width: 1920, Height: 1080},
+	}
+	if len(outputs) != len(want) {
+		t.Fatalf("parseXrandr() returned %d outputs, want %d", len(outputs), len(want))
+	}
+	for i := range want {
+		if outputs[i] != want[i] {
+			t.Errorf("outputs[%d] = %+v, want %+v", i, outputs[i], want[i])
+		}
+	}
+}
+
+func TestParseWlrRandr(t *testing.T) {
+	out := `DP-1 "Dell Inc. DELL U2415 (DP-1)"
+  Enabled: yes
+  Modes:
+    1920x1200 px, 59.950001 Hz (preferred, current)
+    1920x1080 px, 60.000000 Hz
+  Position: 1920,0
+  Scale: 1.000000
+HDMI-A-1 "Unknown (HDMI-A-1)"
+  Enabled: no
+  Modes:
+    1920x1080 px, 60.000000 Hz (preferred)
+eDP-1 "Sharp (eDP-1)"
+  Enabled: yes
+  Modes:
+    1920x1080 px, 60.000000 Hz (preferred, current)
+  Position: 0,0
+`
+	outputs := parseWlrRandr(out)
+
+	want := []Output{
+		{Name: "DP-1", Index: 0, X: 1920, Y: 0, Width: 1920, Height: 1200},
+		{Name: "eDP-1", Index: 1, X: 0, Y: 0, Width: 1920, Height: 1080},
+	}
+	if len(outputs) != len(want) {
+		t.Fatalf("parseWlrRandr() returned %d outputs, want %d", len(outputs), len(want))
+	}
+	for i := range want {
+		if outputs[i] != want[i] {
+			t.Errorf("outputs[%d] = %+v, want %+v", i, outputs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Outputs returns the active outputs sorted from left to right & then
+// top to bottom. Sway is asked through its ipc socket, other wayland
+// compositors with wlr-randr & X11 with xrandr.
+func Outputs() ([]Output, error) {
+	var outputs []Output
+	var err error
+
+	switch {
+	case len(os.Getenv("SWAYSOCK")) != 0:
+		outputs, err = swayOutputs()
+	case len(os.Getenv("WAYLAND_DISPLAY")) != 0:
+		outputs, err = wlrOutputs()
+	default:
+		outputs, err = xrandrOutputs()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("outputs_unix.go: no active output found")
+	}
+
+	sortOutputs(outputs)
+	return outputs, nil
+}
+
+// sortOutputs sorts outputs from left to right & then top to bottom.
+func sortOutputs(outputs []Output) {
+	sort.SliceStable(outputs, func(i, j int) bool {
+		if outputs[i].X != outputs[j].X {
+			return outputs[i].X < outputs[j].X
+		}
+		return outputs[i].Y < outputs[j].Y
+	})
+}
+
+// swayOutputs returns the active outputs reported by sway.
+func swayOutputs() ([]Output, error) {
+	conn, err := swayDial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	out, err := swayMsg(conn, swayGetOutputs, "")
+	if err != nil {
+		return nil, err
+	}
+
+	res := []struct {
+		Name   string `json:"name"`
+		Active bool   `json:"active"`
+		Rect   struct {
+			X      int `json:"x"`
+			Y      int `json:"y"`
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"rect"`
+	}{}
+	err = json.Unmarshal(out, &res)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"outputs_unix.go: failed to unmarshal sway outputs",
+			err.Error())
+	}
+
+	outputs := []Output{}
+	for _, r := range res {
+		if !r.Active {
+			continue
+		}
+		outputs = append(outputs, Output{
+			Name:   r.Name,
+			Index:  len(outputs),
+			X:      r.Rect.X,
+			Y:      r.Rect.Y,
+			Width:  r.Rect.Width,
+			Height: r.Rect.Height,
+		})
+	}
+	return outputs, nil
+}
+
+// wlrOutputs returns the enabled outputs reported by wlr-randr, the
+// size is the current mode of the output.
+func wlrOutputs() ([]Output, error) {
+	out, err := exec.Command("wlr-randr").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"outputs_unix.go: failed to list outputs with wlr-randr",
+			err.Error())
+	}
+	return parseWlrRandr(string(out)), nil
+}
+
+// parseWlrRandr parses the output of wlr-randr. Every output starts
+// with its name on an unindented line, its properties are indented.
+func parseWlrRandr(out string) []Output {
+	mode := regexp.MustCompile(`^\s+(\d+)x(\d+) px[^\n]*current`)
+	pos := regexp.MustCompile(`^\s+Position: (-?\d+),(-?\d+)`)
+
+	outputs := []Output{}
+	var o *Output
+	enabled := true
+
+	add := func() {
+		if o != nil && enabled && o.Width > 0 && o.Height > 0 {
+			o.Index = len(outputs)
+			outputs = append(outputs, *o)
+		}
+	}
+
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case len(strings.TrimSpace(line)) == 0:
+			continue
+
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t"):
+			add()
+			o = &Output{Name: strings.Fields(line)[0]}
+			enabled = true
+
+		case o == nil:
+			continue
+
+		case strings.HasPrefix(strings.TrimSpace(line), "Enabled:"):
+			enabled = strings.TrimSpace(strings.TrimPrefix(
+				strings.TrimSpace(line), "Enabled:")) == "yes"
+
+		default:
+			if m := mode.FindStringSubmatch(line); m != nil {
+				o.Width, _ = strconv.Atoi(m[1])
+				o.Height, _ = strconv.Atoi(m[2])
+			}
+			if m := pos.FindStringSubmatch(line); m != nil {
+				o.X, _ = strconv.Atoi(m[1])
+				o.Y, _ = strconv.Atoi(m[2])
+			}
+		}
+	}
+	add()
+	return outputs
+}
+
+// xrandrOutputs returns the active monitors reported by xrandr.
+func xrandrOutputs() ([]Output, error) {
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"outputs_unix.go: failed to list monitors with xrandr",
+			err.Error())
+	}
+	return parseXrandr(string(out)), nil
+}
+
+// parseXrandr parses the output of `xrandr --listmonitors`, every
+// monitor is listed as:
+//
+//	0: +*eDP-1 1920/344x1080/194+0+0  eDP-1
+//
+// The index is the order in which xinerama reports the monitors.
+func parseXrandr(out string) []Output {
+	re := regexp.MustCompile(`^\s*(\d+): \S+ (\d+)/\d+x(\d+)/\d+\+(-?\d+)\+(-?\d+)\s+(\S+)`)
+
+	outputs := []Output{}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		m := re.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+
+		o := Output{Name: m[6]}
+		o.Index, _ = strconv.Atoi(m[1])
+		o.Width, _ = strconv.Atoi(m[2])
+		o.Height, _ = strconv.Atoi(m[3])
+		o.X, _ = strconv.Atoi(m[4])
+		o.Y, _ = strconv.Atoi(m[5])
+		outputs = append(outputs, o)
+	}
+	return outputs
+}
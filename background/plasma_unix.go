@@ -0,0 +1,42 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// plasmaScript is evaluated by plasmashell, it sets the wallpaper of
+// every desktop containment. FillMode 2 is "Scaled and Cropped", this
+// is the same as feh's --bg-fill.
+const plasmaScript = `var all = desktops();
+for (var i = 0; i < all.length; i++) {
+    var d = all[i];
+    d.wallpaperPlugin = "org.kde.image";
+    d.currentConfigGroup = Array("Wallpaper", "org.kde.image", "General");
+    d.writeConfig("Image", %s);
+    d.writeConfig("FillMode", 2);
+}`
+
+// setPlasma sets the background on KDE Plasma by calling
+// evaluateScript on org.kde.PlasmaShell over D-Bus. dbus-send waits
+// for the reply with --print-reply, otherwise errors in the script are
+// not returned.
+func setPlasma(path string) error {
+	uri := fmt.Sprintf("%s%s", "file://", path)
+	script := fmt.Sprintf(plasmaScript, strconv.Quote(uri))
+
+	out, err := exec.Command("dbus-send", "--session", "--print-reply",
+		"--type=method_call", "--dest=org.kde.plasmashell", "/PlasmaShell",
+		"org.kde.PlasmaShell.evaluateScript",
+		fmt.Sprintf("%s%s", "string:", script)).CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s\n%s",
+			"plasma_unix.go: failed to set background with dbus-send",
+			strings.TrimSpace(string(out)), err.Error())
+	}
+	return err
+}
@@ -8,12 +8,25 @@ import (
 	"strconv"
 )
 
-// SetFromFile takes a string as an input, it must be absolute path to
-// the background. Checks are not made to check if the path exists or
-// it is actually an image, that must be verified before passing it to
-// SetFromFile. SetFromFile will exit returning in error if there is
-// any.
-func SetFromFile(path string) error {
+// backends holds every backend on macOS, osascript is the only one.
+var backends = []backend{
+	{"osascript", "osascript", "macOS", setOsascript},
+}
+
+// outputSetters & spannedSetters are empty because osascript sets the
+// same background on every desktop.
+var (
+	outputSetters  = map[string]func([]Output, []string) error{}
+	spannedSetters = map[string]func(string) error{}
+)
+
+// detect returns osascript because it's the only backend on macOS.
+func detect() (string, string) {
+	return "osascript", "only backend on macOS"
+}
+
+// setOsascript sets the background of every desktop with osascript.
+func setOsascript(path string) error {
 	err := exec.Command("osascript", "-e",
 		`tell application "System Events" to tell every desktop to set picture to `+strconv.Quote(path)).Run()
 	if err != nil {
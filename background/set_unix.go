@@ -8,59 +8,250 @@ import (
 	"os/exec"
 )
 
-// SetFromFile takes a string as an input, it must be absolute path to
-// the background. Checks are not made to check if the path exists or
-// it is actually an image, that must be verified before passing it to
-// SetFromFile. SetFromFile will exit returning in error if there is
-// any.
-func SetFromFile(path string) error {
-	var err error
-	switch os.Getenv("XDG_CURRENT_DESKTOP") {
+// backends holds every backend on unix, detect chooses one of them
+// depending on the environment.
+var backends = []backend{
+	{"gsettings", "gsettings", "GNOME, Unity & Pantheon", setGnome},
+	{"cinnamon", "gsettings", "Cinnamon", setCinnamon},
+	{"mate", "gsettings", "MATE", setMate},
+	{"xfconf", "xfconf-query", "XFCE", setXfce},
+	{"plasma", "dbus-send", "KDE Plasma", setPlasma},
+	{"pcmanfm", "pcmanfm", "LXDE", setPcmanfm},
+	{"sway", "", "sway (ipc socket)", setSwayAll},
+	{"swaybg", "swaybg", "wlroots based compositors", setSwaybg},
+	{"feh", "feh", "X11 window managers", setFeh},
+	{"xwallpaper", "xwallpaper", "X11 window managers", setXwallpaper},
+	{"x11", "", "X11 window managers (built-in)", setX11},
+}
+
+// outputSetters holds backends that can set a different background on
+// every output.
+var outputSetters = map[string]func([]Output, []string) error{
+	"xfconf":     setXfceOutputs,
+	"sway":       setSwayOutputs,
+	"swaybg":     setSwaybgOutputs,
+	"feh":        setFehOutputs,
+	"xwallpaper": setXwallpaperOutputs,
+}
+
+// spannedSetters holds backends that can set a single background
+// spanning all outputs. Root window covers every output so x11
+// backend spans the background by default.
+var spannedSetters = map[string]func(string) error{
+	"gsettings": setGnomeSpanned,
+	"cinnamon":  setCinnamonSpanned,
+	"mate":      setMateSpanned,
+	"pcmanfm":   setPcmanfmSpanned,
+	"feh":       setFehSpanned,
+	"x11":       setX11,
+}
+
+// detect chooses the backend depending on XDG_CURRENT_DESKTOP, if it
+// doesn't match any desktop then wayland & X11 backends are tried.
+func detect() (string, string) {
+	desktop := os.Getenv("XDG_CURRENT_DESKTOP")
+	reason := fmt.Sprintf("XDG_CURRENT_DESKTOP=%q", desktop)
+
+	switch desktop {
 	case "GNOME", "Unity", "Pantheon":
 		// GNOME, Unity & Pantheon support setting background
 		// from gsettings & have the same key.
+		return "gsettings", reason
 
-		// gsettings takes path in format of a uri
-		path = fmt.Sprintf("%s%s", "file://", path)
+	case "X-Cinnamon":
+		return "cinnamon", reason
 
-		err = exec.Command("gsettings",
-			"set org.gnome.desktop.background picture-uri", path).Run()
-		if err != nil {
-			err = fmt.Errorf("%s\n%s",
-				"set_unix.go: failed to set background with gsettings",
-				err.Error())
-		}
-		return err
+	case "MATE":
+		return "mate", reason
+
+	case "XFCE":
+		return "xfconf", reason
+
+	case "KDE":
+		// Plasma draws its own desktop over the root window,
+		// it's set with a script evaluated by plasmashell.
+		return "plasma", reason
 
 	case "LXDE":
 		// Background on LXDE can be set with pcmanfm (default
 		// file manager).
-		err = exec.Command("pcmanfm", "-w", path).Run()
-		if err != nil {
-			err = fmt.Errorf("%s\n%s",
-				"set_unix.go: failed to set background with pcmanfm",
-				err.Error())
-		}
+		return "pcmanfm", reason
+	}
+
+	switch {
+	case len(os.Getenv("SWAYSOCK")) != 0:
+		// Sway sets SWAYSOCK, background is set through its
+		// ipc socket.
+		return "sway", "SWAYSOCK is set"
+
+	case len(os.Getenv("WAYLAND_DISPLAY")) != 0:
+		// feh doesn't work on wayland, swaybg works on every
+		// wlroots based compositor.
+		return "swaybg", "WAYLAND_DISPLAY is set & desktop is unknown"
+	}
+
+	// If WM/DE doesn't have a case then feh is used to set the
+	// background. This is tested to work on WMs similar to i3wm.
+	// If feh is not installed then root window background is set
+	// directly.
+	if _, err := exec.LookPath("feh"); err != nil {
+		return "x11", "desktop is unknown & feh is not in $PATH"
+	}
+	return "feh", "desktop is unknown"
+}
+
+// setCinnamon sets the background on Cinnamon with gsettings.
+func setCinnamon(path string) error {
+	path = fmt.Sprintf("%s%s", "file://", path)
+
+	err := exec.Command("gsettings", "set",
+		"org.cinnamon.desktop.background", "picture-uri", path).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with gsettings (cinnamon)",
+			err.Error())
+	}
+	return err
+}
+
+// setMate sets the background on MATE with gsettings, MATE takes the
+// path as is instead of a uri.
+func setMate(path string) error {
+	err := exec.Command("gsettings", "set",
+		"org.mate.background", "picture-filename", path).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with gsettings (mate)",
+			err.Error())
+	}
+	return err
+}
+
+// setCinnamonSpanned sets path as a single background spanning all
+// outputs on Cinnamon.
+func setCinnamonSpanned(path string) error {
+	err := exec.Command("gsettings", "set",
+		"org.cinnamon.desktop.background", "picture-options", "spanned").Run()
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set picture-options with gsettings (cinnamon)",
+			err.Error())
+	}
+	return setCinnamon(path)
+}
+
+// setMateSpanned sets path as a single background spanning all
+// outputs on MATE.
+func setMateSpanned(path string) error {
+	err := exec.Command("gsettings", "set",
+		"org.mate.background", "picture-options", "spanned").Run()
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set picture-options with gsettings (mate)",
+			err.Error())
+	}
+	return setMate(path)
+}
+
+// setPcmanfm sets the background with pcmanfm.
+func setPcmanfm(path string) error {
+	err := exec.Command("pcmanfm", "-w", path).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with pcmanfm",
+			err.Error())
+	}
+	return err
+}
+
+// setPcmanfmSpanned sets path as a single background spanning all
+// outputs with pcmanfm, "screen" mode stretches it over every
+// monitor.
+func setPcmanfmSpanned(path string) error {
+	err := exec.Command("pcmanfm", "-w", path, "--wallpaper-mode=screen").Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with pcmanfm",
+			err.Error())
+	}
+	return err
+}
+
+// setSwayAll sets the background of every sway output.
+func setSwayAll(path string) error {
+	return setSway(path, "*")
+}
+
+// setFeh sets the background with feh.
+func setFeh(path string) error {
+	return runFeh("--bg-fill", path)
+}
+
+// runFeh runs feh with args.
+func runFeh(args ...string) error {
+	feh, err := exec.LookPath("feh")
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: feh not found in $PATH",
+			err.Error())
 		return err
+	}
 
-	default:
-		// If WM/DE doesn't have a case then feh is used to
-		// set the background. This is tested to work on WMs
-		// similar to i3wm.
-		feh, err := exec.LookPath("feh")
-		if err != nil {
-			err = fmt.Errorf("%s\n%s",
-				"set_unix.go: feh not found in $PATH",
-				err.Error())
-			return err
-		}
+	err = exec.Command(feh, args...).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with feh",
+			err.Error())
+	}
+	return err
+}
 
-		err = exec.Command(feh, "--bg-fill", path).Run()
-		if err != nil {
-			err = fmt.Errorf("%s\n%s",
-				"set_unix.go: failed to set background with feh",
-				err.Error())
+// setFehSpanned sets path as a single background spanning all outputs
+// with feh, --no-xinerama treats the outputs as one screen.
+func setFehSpanned(path string) error {
+	return runFeh("--bg-fill", "--no-xinerama", path)
+}
+
+// setFehOutputs sets files[i] as the background of outputs[i] with
+// feh. feh takes one file per output in the order reported by
+// xinerama which is the order of Index.
+func setFehOutputs(outputs []Output, files []string) error {
+	ordered := make([]string, len(files))
+	for i, o := range outputs {
+		if o.Index < 0 || o.Index >= len(ordered) {
+			return fmt.Errorf("set_unix.go: invalid index of output %s: %d",
+				o.Name, o.Index)
 		}
-		return err
+		ordered[o.Index] = files[i]
+	}
+	return runFeh(append([]string{"--bg-fill"}, ordered...)...)
+}
+
+// setXwallpaper sets the background with xwallpaper, --zoom is the
+// same as feh's --bg-fill.
+func setXwallpaper(path string) error {
+	err := exec.Command("xwallpaper", "--zoom", path).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with xwallpaper",
+			err.Error())
+	}
+	return err
+}
+
+// setXwallpaperOutputs sets files[i] as the background of outputs[i]
+// with xwallpaper.
+func setXwallpaperOutputs(outputs []Output, files []string) error {
+	args := []string{}
+	for i, o := range outputs {
+		args = append(args, "--output", o.Name, "--zoom", files[i])
+	}
+
+	err := exec.Command("xwallpaper", args...).Run()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"set_unix.go: failed to set background with xwallpaper",
+			err.Error())
 	}
+	return err
 }
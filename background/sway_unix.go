@@ -0,0 +1,235 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"tildegit.org/andinus/cetus/cache"
+)
+
+// swayMagic is sent at the start of every message to sway ipc socket.
+const swayMagic = "i3-ipc"
+
+// Types of messages sent to sway ipc socket, swayRunCommand runs sway
+// commands & swayGetOutputs returns the list of outputs.
+const (
+	swayRunCommand = 0
+	swayGetOutputs = 3
+)
+
+// setSway sets the background of output through sway ipc socket,
+// output is the name of the output or "*" for every output.
+func setSway(path, output string) error {
+	return swayCommand(swayBg(path, output))
+}
+
+// setSwayOutputs sets files[i] as the background of outputs[i]
+// through sway ipc socket. Commands are sent together so that every
+// output changes at once.
+func setSwayOutputs(outputs []Output, files []string) error {
+	cmds := []string{}
+	for i, o := range outputs {
+		cmds = append(cmds, swayBg(files[i], strconv.Quote(o.Name)))
+	}
+	return swayCommand(strings.Join(cmds, "; "))
+}
+
+// swayBg returns the sway command that sets path as background of
+// output.
+func swayBg(path, output string) string {
+	return fmt.Sprintf("output %s bg %s fill", output, strconv.Quote(path))
+}
+
+// swayCommand runs cmd through sway ipc socket & returns an error if
+// any of the commands failed.
+func swayCommand(cmd string) error {
+	conn, err := swayDial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := swayMsg(conn, swayRunCommand, cmd)
+	if err != nil {
+		return err
+	}
+
+	// Sway returns a list of results, one for every command.
+	res := []struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}{}
+	err = json.Unmarshal(out, &res)
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"sway_unix.go: failed to unmarshal sway response",
+			err.Error())
+	}
+	for _, r := range res {
+		if !r.Success {
+			return fmt.Errorf("sway_unix.go: sway failed to set background: %s",
+				r.Error)
+		}
+	}
+	return nil
+}
+
+// swayDial connects to sway ipc socket, SWAYSOCK holds the path to
+// it.
+func swayDial() (net.Conn, error) {
+	conn, err := net.Dial("unix", os.Getenv("SWAYSOCK"))
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"sway_unix.go: failed to connect to sway ipc socket",
+			err.Error())
+	}
+	return conn, nil
+}
+
+// swayMsg sends payload of type t on conn & returns the payload of the
+// reply. Sway uses native byte order, we assume it to be little endian
+// which is true for every architecture that sway runs on in practice.
+func swayMsg(conn net.Conn, t uint32, payload string) ([]byte, error) {
+	msg := new(bytes.Buffer)
+	msg.WriteString(swayMagic)
+	binary.Write(msg, binary.LittleEndian, uint32(len(payload)))
+	binary.Write(msg, binary.LittleEndian, t)
+	msg.WriteString(payload)
+
+	_, err := conn.Write(msg.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"sway_unix.go: failed to write to sway ipc socket",
+			err.Error())
+	}
+
+	header := make([]byte, len(swayMagic)+8)
+	_, err = io.ReadFull(conn, header)
+	if err != nil || string(header[:len(swayMagic)]) != swayMagic {
+		return nil, fmt.Errorf("sway_unix.go: invalid reply from sway ipc socket")
+	}
+
+	size := binary.LittleEndian.Uint32(header[len(swayMagic):])
+	out := make([]byte, size)
+	_, err = io.ReadFull(conn, out)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"sway_unix.go: failed to read reply from sway ipc socket",
+			err.Error())
+	}
+	return out, nil
+}
+
+// setSwaybg sets the background on wlroots based compositors by
+// running swaybg. swaybg has to keep running for the background to
+// stay, so we start a new process & then stop the one started
+// earlier. Starting the new one first avoids flicker.
+func setSwaybg(path string) error {
+	return startSwaybg("-i", path, "-m", "fill")
+}
+
+// setSwaybgOutputs sets files[i] as the background of outputs[i] by
+// running a single swaybg for every output.
+func setSwaybgOutputs(outputs []Output, files []string) error {
+	args := []string{}
+	for i, o := range outputs {
+		args = append(args, "-o", o.Name, "-i", files[i], "-m", "fill")
+	}
+	return startSwaybg(args...)
+}
+
+// startSwaybg starts swaybg with args & stops the one started
+// earlier.
+func startSwaybg(args ...string) error {
+	swaybg, err := exec.LookPath("swaybg")
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"sway_unix.go: swaybg not found in $PATH",
+			err.Error())
+	}
+
+	cmd := exec.Command(swaybg, args...)
+
+	// Run swaybg in its own session so that it isn't killed
+	// along with the terminal cetus was run from.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"sway_unix.go: failed to start swaybg",
+			err.Error())
+	}
+
+	pidFile := swaybgPidFile()
+	killSwaybg(pidFile)
+
+	err = ioutil.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0644)
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"sway_unix.go: failed to write pid to file: ", pidFile,
+			err.Error())
+	}
+	cmd.Process.Release()
+	return err
+}
+
+// swaybgPidFile returns the path to file that holds pid of swaybg
+// started by cetus. It's kept in XDG_RUNTIME_DIR because that is only
+// writable by the user, cache directory is used if it's not set.
+func swaybgPidFile() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if len(dir) == 0 {
+		dir = cache.GetDir()
+		os.MkdirAll(dir, os.ModePerm)
+	}
+	return filepath.Join(dir, "cetus-swaybg.pid")
+}
+
+// killSwaybg stops swaybg whose pid is in pidFile. Name of the process
+// is verified so that we don't kill an unrelated process that got the
+// same pid, it's not stopped if the name can't be found. Errors are
+// ignored, old swaybg not being stopped is not fatal.
+func killSwaybg(pidFile string) {
+	data, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		return
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return
+	}
+
+	if processName(pid) != "swaybg" {
+		return
+	}
+	syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// processName returns the name of process with pid, it's read from
+// /proc on Linux & from ps on systems without /proc. An empty string
+// is returned if it can't be found.
+func processName(pid int) string {
+	comm, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err == nil {
+		return strings.TrimSpace(string(comm))
+	}
+
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,51 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	// Decoders for formats that the image package supports.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"tildegit.org/andinus/cetus/fit"
+	"tildegit.org/andinus/cetus/x11"
+)
+
+// setX11 sets the background of X11 root window without any external
+// program. The image is decoded & scaled to fill the screen, then
+// it's drawn on a pixmap that is set as root window background.
+func setX11(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"x11_unix.go: failed to open file: ", path,
+			err.Error())
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"x11_unix.go: failed to decode image: ", path,
+			err.Error())
+	}
+
+	c, err := x11.Connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	err = c.SetRoot(fit.Fill(img, c.Screen.Width, c.Screen.Height))
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"x11_unix.go: failed to set root window background",
+			err.Error())
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package background
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// setXfce sets the background on XFCE with xfconf-query. XFCE has a
+// last-image property for every monitor & workspace, all of them are
+// set. image-style 5 is "Zoomed", this is the same as feh's
+// --bg-fill.
+func setXfce(path string) error {
+	return setXfceProps(func(prop string) string {
+		return path
+	})
+}
+
+// setXfceOutputs sets files[i] as the background of outputs[i] on
+// XFCE. Properties of a monitor have "monitor<name>" in their path,
+// monitors that don't match any output are left as is.
+func setXfceOutputs(outputs []Output, files []string) error {
+	return setXfceProps(func(prop string) string {
+		for i, o := range outputs {
+			if strings.Contains(prop, "/monitor"+o.Name+"/") {
+				return files[i]
+			}
+		}
+		return ""
+	})
+}
+
+// setXfceProps sets every last-image property to the path returned
+// by image, properties for which it returns an empty string are
+// skipped. image-style of the same monitor is set to "Zoomed".
+func setXfceProps(image func(prop string) string) error {
+	out, err := exec.Command("xfconf-query", "-c", "xfce4-desktop", "-l").Output()
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"xfce_unix.go: failed to list properties with xfconf-query",
+			err.Error())
+	}
+
+	found := false
+	for _, prop := range strings.Fields(string(out)) {
+		img := image(prop)
+		if len(img) == 0 {
+			continue
+		}
+
+		value := ""
+		switch {
+		case strings.HasSuffix(prop, "/last-image"):
+			value = img
+			found = true
+		case strings.HasSuffix(prop, "/image-style"):
+			value = "5"
+		default:
+			continue
+		}
+
+		err = exec.Command("xfconf-query", "-c", "xfce4-desktop",
+			"-p", prop, "-s", value).Run()
+		if err != nil {
+			return fmt.Errorf("%s%s\n%s",
+				"xfce_unix.go: failed to set property with xfconf-query: ", prop,
+				err.Error())
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("xfce_unix.go: no last-image property found in xfce4-desktop")
+	}
+	return nil
+}
@@ -43,7 +43,9 @@ func MarshalJson(res BPOD) (string, error) {
 	return body, err
 }
 
-// UnmarshalJson will take body as input & unmarshal it to res,
+// UnmarshalJson will take body as input & unmarshal it to res, if body
+// contains more than one photo then a random one is chosen. body can
+// also be a single BPOD which is the format in which it's cached.
 func UnmarshalJson(body string) (BPOD, error) {
 	list := List{}
 	res := BPOD{}
@@ -53,6 +55,14 @@ func UnmarshalJson(body string) (BPOD, error) {
 		return res, fmt.Errorf("UnmarshalJson failed\n%s", err.Error())
 	}
 
+	if len(list.Photos) == 0 {
+		err = json.Unmarshal([]byte(body), &res)
+		if err != nil || len(res.URL) == 0 {
+			return res, fmt.Errorf("UnmarshalJson failed: no photo in body")
+		}
+		return res, nil
+	}
+
 	res = list.Photos[rand.Intn(len(list.Photos))]
 	return res, nil
 }
@@ -60,7 +70,7 @@ func UnmarshalJson(body string) (BPOD, error) {
 // GetJson takes reqInfo as input and returns the body and an error.
 func GetJson(reqInfo map[string]string) (string, error) {
 	// reqInfo is map[string]string and params is built from it,
-	// currently it takes apiKey and the date from reqInfo to
+	// currently it takes random and idx from reqInfo to
 	// build param. If any new key/value is added to reqInfo then
 	// it must be addded here too, it won't be sent as param
 	// directly.
@@ -73,6 +83,11 @@ func GetJson(reqInfo map[string]string) (string, error) {
 		params["n"] = "7"
 	}
 
+	// idx is the number of days before today of the first photo.
+	if len(reqInfo["idx"]) != 0 {
+		params["idx"] = reqInfo["idx"]
+	}
+
 	body, err := request.GetRes(reqInfo["api"], params)
 	return string(body), err
 }
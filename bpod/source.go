@@ -0,0 +1,233 @@
+package bpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// maxIdx is the number of days before today of the oldest photo served
+// by bing.
+const maxIdx = 7
+
+// Source implements source.Source for Bing Photo of the Day. Cache is
+// the directory where cetus caches BPOD, it's used to find the
+// current photo without making a request.
+type Source struct {
+	API   string
+	Cache string
+}
+
+// Date returns the date of BPOD that should be fetched for q. If date
+// was not passed then it's the date of Bing's current photo if that is
+// cached along with its image, otherwise an empty string is returned
+// because the date is only known after making the request. It can
+// differ from the local date.
+func (s *Source) Date(q source.Query) (string, error) {
+	if q.Random {
+		return "", nil
+	}
+	if len(q.Date) == 0 {
+		return s.cachedCurrent(time.Now()), nil
+	}
+	_, err := time.Parse("2006-01-02", q.Date)
+	if err != nil {
+		return "", fmt.Errorf("source.go: invalid date: %q", q.Date)
+	}
+	return q.Date, nil
+}
+
+// Fetch returns the response body, it'll contain 7 photos if q.Random
+// is true. Bing only serves photos of the last 8 days, an error is
+// returned if q.Date is older than that. It's only called if BPOD of
+// q.Date is not in cache.
+func (s *Source) Fetch(q source.Query) (string, error) {
+	// reqInfo holds all the parameters that needs to be sent with
+	// the request. GetJson() will pack random in params map
+	// before sending it to another function. Adding params here
+	// will not change the behaviour of the function, changes
+	// have to be made in GetJson() too.
+	reqInfo := make(map[string]string)
+	reqInfo["api"] = s.API
+
+	if q.Random {
+		reqInfo["random"] = "true"
+	}
+
+	if len(q.Date) != 0 {
+		dt, err := time.Parse("2006-01-02", q.Date)
+		if err != nil {
+			return "", fmt.Errorf("source.go: invalid date: %q", q.Date)
+		}
+
+		// Date of Bing's current photo differs from the local
+		// date by a day at most, dates that are far off are
+		// rejected before making the request.
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if days := daysBetween(dt, today); days < -1 || days > maxIdx+1 {
+			return "", fmt.Errorf("source.go: bpod of %s is not in cache & bing only serves the last %d days",
+				q.Date, maxIdx+1)
+		}
+
+		// idx is the number of days before Bing's current
+		// photo, 0 is the current photo.
+		body, err := s.fetch(reqInfo)
+		if err != nil {
+			return "", err
+		}
+		cur, err := s.Parse(body)
+		if err != nil {
+			return "", err
+		}
+		curDate, err := time.Parse("2006-01-02", cur.Date)
+		if err != nil {
+			return "", err
+		}
+		idx := daysBetween(dt, curDate)
+		if idx == 0 {
+			return body, nil
+		}
+		if idx < 0 || idx > maxIdx {
+			return "", fmt.Errorf("source.go: bpod of %s is not in cache & bing only serves %d days before %s",
+				q.Date, maxIdx, cur.Date)
+		}
+		reqInfo["idx"] = strconv.Itoa(idx)
+
+		body, err = s.fetch(reqInfo)
+		if err != nil {
+			return "", err
+		}
+		pic, err := s.Parse(body)
+		if err != nil {
+			return "", err
+		}
+		if pic.Date != q.Date {
+			return "", fmt.Errorf("source.go: bing returned bpod of %s instead of %s",
+				pic.Date, q.Date)
+		}
+		return body, nil
+	}
+
+	return s.fetch(reqInfo)
+}
+
+// cachedCurrent returns the date of BPOD in cache that Bing serves as
+// the current photo at now, an empty string is returned if there is
+// none or its image isn't cached.
+func (s *Source) cachedCurrent(now time.Time) string {
+	if len(s.Cache) == 0 {
+		return ""
+	}
+	files, _ := filepath.Glob(filepath.Join(s.Cache, "*.json"))
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := ioutil.ReadFile(files[i])
+		if err != nil {
+			continue
+		}
+		res, err := UnmarshalJson(string(data))
+		if err != nil || !current(res, now) {
+			continue
+		}
+
+		date := strings.TrimSuffix(filepath.Base(files[i]), ".json")
+		images, _ := filepath.Glob(filepath.Join(s.Cache, date+"-*"))
+		for _, img := range images {
+			if filepath.Ext(img) != ".part" {
+				return date
+			}
+		}
+	}
+	return ""
+}
+
+// current returns true if res is Bing's current photo at now. It's
+// served for a day from fullstartdate which is in UTC, startdate &
+// enddate are used if it's not set.
+func current(res BPOD, now time.Time) bool {
+	start, err := time.Parse("200601021504", res.FullStartDate)
+	end := start.AddDate(0, 0, 1)
+	if err != nil {
+		start, err = parseDate(res.StartDate)
+		if err != nil {
+			return false
+		}
+		end, err = parseDate(res.EndDate)
+		if err != nil {
+			end = start.AddDate(0, 0, 1)
+		}
+	}
+	return !now.Before(start) && now.Before(end)
+}
+
+// parseDate parses date in the format used by Bing or by the cache.
+func parseDate(date string) (time.Time, error) {
+	dt, err := time.Parse("20060102", date)
+	if err != nil {
+		dt, err = time.Parse("2006-01-02", date)
+	}
+	return dt, err
+}
+
+// fetch returns the response body for request described by reqInfo.
+func (s *Source) fetch(reqInfo map[string]string) (string, error) {
+	body, err := GetJson(reqInfo)
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"source.go: failed to get json response from api",
+			err.Error())
+	}
+	return body, err
+}
+
+// daysBetween returns the number of days from a to b, both must be
+// dates at midnight in UTC so that every day is 24 hours.
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours()) / 24
+}
+
+// Parse converts body to source.Picture, if body contains more than
+// one photo then a random one is chosen.
+func (s *Source) Parse(body string) (source.Picture, error) {
+	pic := source.Picture{}
+
+	res, err := UnmarshalJson(body)
+	if err != nil {
+		return pic, err
+	}
+
+	// Correct format, cached body is already in correct format.
+	if strings.HasPrefix(res.URL, "/") {
+		res.URL = fmt.Sprintf("%s%s", "https://www.bing.com", res.URL)
+	}
+	dt, err := parseDate(res.StartDate)
+	if err != nil {
+		return pic, err
+	}
+	res.StartDate = dt.Format("2006-01-02")
+
+	pic.Service = "bpod"
+	pic.Date = res.StartDate
+	pic.Title = res.Title
+	pic.Credit = res.Copyright
+	pic.CreditURL = res.CopyrightLink
+	pic.MediaType = "image"
+	pic.URL = res.URL
+
+	// Save response in cache after marshalling it again, we do
+	// this instead of saving the response so as to not break the
+	// format in which cache is saved. If random flag was passed
+	// then the response will contain all 7 values so we have to
+	// marshal it but why not save non-random directly? Because
+	// that means the format in which both are saved will be
+	// different. One will be the raw response whereas other will
+	// be marshalled response. Cached body is read when BPOD of the
+	// same date is requested again.
+	pic.Body, err = MarshalJson(res)
+	return pic, err
+}
@@ -0,0 +1,167 @@
+package bpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// TestParse tests if Parse handles both the api response & the cached
+// body that it returns.
+func TestParse(t *testing.T) {
+	body := `{"images": [{"startdate": "20200425",
+"url": "/th?id=OHR.Example_1920x1080.jpg", "title": "Example"}]}`
+
+	s := Source{}
+	pic, err := s.Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pic.Date != "2020-04-25" {
+		t.Errorf("Date is incorrect, got %q, want %q.", pic.Date, "2020-04-25")
+	}
+
+	want := "https://www.bing.com/th?id=OHR.Example_1920x1080.jpg"
+	if pic.URL != want {
+		t.Errorf("URL is incorrect, got %q, want %q.", pic.URL, want)
+	}
+
+	cached, err := s.Parse(pic.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.URL != pic.URL || cached.Date != pic.Date {
+		t.Errorf("Parsing cached body returned %v, want %v.", cached, pic)
+	}
+}
+
+// TestDate tests if date is left empty when it's not passed because
+// Bing's current date is only known after the request.
+func TestDate(t *testing.T) {
+	s := Source{}
+
+	got, err := s.Date(source.Query{})
+	if err != nil || got != "" {
+		t.Errorf("Date returned %q, %v, want empty date.", got, err)
+	}
+	got, err = s.Date(source.Query{Random: true})
+	if err != nil || got != "" {
+		t.Errorf("Date with random returned %q, %v, want empty date.", got, err)
+	}
+	_, err = s.Date(source.Query{Date: "25-04-2020"})
+	if err == nil {
+		t.Errorf("Date didn't return an error on invalid date.")
+	}
+
+	_, err = s.Fetch(source.Query{Date: "2000-01-01"})
+	if err == nil {
+		t.Errorf("Fetch didn't return an error on date older than %d days.", maxIdx+1)
+	}
+}
+
+// TestFetchDate tests if idx is counted from the date of Bing's
+// current photo, which is a day behind the local date here.
+func TestFetchDate(t *testing.T) {
+	now := time.Now()
+	current := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+
+	idxs := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idx := r.URL.Query().Get("idx")
+		idxs = append(idxs, idx)
+		n, _ := strconv.Atoi(idx)
+		fmt.Fprintf(w, `{"images": [{"startdate": %q, "url": "/th", "title": "Example"}]}`,
+			current.AddDate(0, 0, -n).Format("20060102"))
+	}))
+	defer ts.Close()
+
+	s := Source{API: ts.URL}
+	tests := []struct {
+		date string
+		idxs []string
+	}{
+		{current.Format("2006-01-02"), []string{""}},
+		{current.AddDate(0, 0, -2).Format("2006-01-02"), []string{"", "2"}},
+	}
+	for _, test := range tests {
+		idxs = []string{}
+		body, err := s.Fetch(source.Query{Date: test.date})
+		if err != nil {
+			t.Errorf("Fetch(%s) returned error: %s", test.date, err)
+			continue
+		}
+		pic, err := s.Parse(body)
+		if err != nil || pic.Date != test.date {
+			t.Errorf("Fetch(%s) returned bpod of %q, %v.", test.date, pic.Date, err)
+		}
+		if strings.Join(idxs, ",") != strings.Join(test.idxs, ",") {
+			t.Errorf("Fetch(%s) requested idx %q, want %q.", test.date, idxs, test.idxs)
+		}
+	}
+
+	// Bing hasn't published the photo of local date yet.
+	_, err := s.Fetch(source.Query{Date: current.AddDate(0, 0, 1).Format("2006-01-02")})
+	if err == nil {
+		t.Errorf("Fetch didn't return an error on date after Bing's current photo.")
+	}
+}
+
+// TestDateCached tests if the date of Bing's current photo is taken
+// from the cache without making a request when it's cached along
+// with its image.
+func TestDateCached(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Current photo started an hour ago, the one before it is
+	// cached too.
+	start := time.Now().UTC().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		st := start.AddDate(0, 0, -i)
+		date := st.Format("2006-01-02")
+		body := fmt.Sprintf(`{"startdate": %q, "fullstartdate": %q, "url": "https://www.bing.com/th"}`,
+			date, st.Format("200601021504"))
+		err = ioutil.WriteFile(filepath.Join(dir, date+".json"), []byte(body), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, date+"-abcd.jpg"), []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := Source{API: ts.URL, Cache: dir}
+	got, err := s.Date(source.Query{})
+	if err != nil || got != start.Format("2006-01-02") {
+		t.Errorf("Date returned %q, %v, want %q.", got, err, start.Format("2006-01-02"))
+	}
+	if requests != 0 {
+		t.Errorf("Date made %d requests, want none.", requests)
+	}
+
+	// Image of the current photo is not cached.
+	os.Remove(filepath.Join(dir, start.Format("2006-01-02")+"-abcd.jpg"))
+	got, err = s.Date(source.Query{})
+	if err != nil || got != "" {
+		t.Errorf("Date without cached image returned %q, %v, want empty date.", got, err)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/history"
+)
+
+// execCache handles cache command, it lists, verifies & removes the
+// cached responses & images.
+func execCache() {
+	if len(os.Args) < 3 {
+		cacheUsage()
+	}
+
+	fs := flag.NewFlagSet("cache "+os.Args[2], flag.ExitOnError)
+	olderThan := fs.String("older-than", "", "Remove files not used for this duration, like 30d")
+	maxSize := fs.String("max-size", "", "Remove least recently used files above this size, like 500M")
+	remove := fs.Bool("remove", false, "Remove files that are not valid")
+	fs.Parse(os.Args[3:])
+
+	migrateCache()
+
+	switch os.Args[2] {
+	case "list":
+		entries := cacheEntries()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Service\tDate\tKind\tSize\tLast Used\tFile")
+		for _, e := range entries {
+			if fs.NArg() > 0 && fs.Arg(0) != e.Service {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				e.Service, e.Date, e.Kind, humanSize(e.Size),
+				e.LastUsed.Format("2006-01-02 15:04"), e.Path)
+		}
+		w.Flush()
+
+	case "stats":
+		printCacheStats(cacheEntries())
+
+	case "verify":
+		invalid := 0
+		for _, e := range cacheEntries() {
+			err := verifyCached(e)
+			if err == nil {
+				continue
+			}
+			invalid++
+			fmt.Printf("%s: %s\n", e.Path, err)
+			if *remove {
+				os.Remove(e.Path)
+			}
+		}
+		if invalid == 0 {
+			fmt.Println("All cached files are valid.")
+		} else if !*remove {
+			fmt.Printf("%d invalid files, run `cetus cache verify -remove` to remove them.\n", invalid)
+			os.Exit(1)
+		}
+
+	case "prune":
+		// Limits are parsed like the settings, configured
+		// limits are used if none were passed.
+		if len(*olderThan) != 0 || len(*maxSize) != 0 {
+			conf.Set("cache.max_age", "0", "cache prune")
+			conf.Set("cache.max_size", "0", "cache prune")
+		}
+		if len(*olderThan) != 0 {
+			conf.Set("cache.max_age", *olderThan, "-older-than")
+		}
+		if len(*maxSize) != 0 {
+			conf.Set("cache.max_size", *maxSize, "-max-size")
+		}
+		age, err := conf.Duration("cache.max_age")
+		if err != nil {
+			log.Fatal(err)
+		}
+		size, err := conf.Size("cache.max_size")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		before := cacheEntries()
+		err = cache.Prune(age, size, currentFiles()...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		after := cacheEntries()
+		fmt.Printf("Removed %d files, freed %s.\n",
+			len(before)-len(after), humanSize(totalSize(before)-totalSize(after)))
+
+	case "clear":
+		entries := cacheEntries()
+		err := cache.Clear()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Removed %d files, freed %s.\n",
+			len(entries), humanSize(totalSize(entries)))
+
+	default:
+		fmt.Printf("Invalid cache command: %q\n", os.Args[2])
+		cacheUsage()
+	}
+}
+
+func cacheUsage() {
+	fmt.Println("Usage: cetus cache list [<service>]")
+	fmt.Println("       cetus cache stats")
+	fmt.Println("       cetus cache verify [-remove]")
+	fmt.Println("       cetus cache prune [-older-than <duration>] [-max-size <size>]")
+	fmt.Println("       cetus cache clear")
+	os.Exit(1)
+}
+
+// cacheEntries returns the entries in cache, it exits on error.
+func cacheEntries() []cache.Entry {
+	entries, err := cache.Entries()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return entries
+}
+
+// printCacheStats prints number of files & size of cache per service
+// along with the limits used to prune it.
+func printCacheStats(entries []cache.Entry) {
+	type stat struct {
+		json, images int
+		size         int64
+	}
+	services := []string{}
+	stats := make(map[string]*stat)
+	for _, e := range entries {
+		s, ok := stats[e.Service]
+		if !ok {
+			s = &stat{}
+			stats[e.Service] = s
+			services = append(services, e.Service)
+		}
+		switch e.Kind {
+		case "json":
+			s.json++
+		case "image":
+			s.images++
+		}
+		s.size += e.Size
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Service\tJSON\tImages\tSize")
+	for _, name := range services {
+		s := stats[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", name, s.json, s.images, humanSize(s.size))
+	}
+	fmt.Fprintf(w, "Total\t\t\t%s\n", humanSize(totalSize(entries)))
+	w.Flush()
+
+	fmt.Printf("\nLimits: max_age = %s, max_size = %s\n",
+		conf.Get("cache.max_age"), conf.Get("cache.max_size"))
+}
+
+// verifyCached returns an error if cached file of e is not valid.
+// Responses must be valid json & images must decode unless Go can't
+// decode their format, incomplete downloads are not checked because
+// they're resumed later.
+func verifyCached(e cache.Entry) error {
+	switch e.Kind {
+	case "json":
+		data, err := ioutil.ReadFile(e.Path)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("not valid json")
+		}
+	case "image":
+		return background.Verify(e.Path)
+	}
+	return nil
+}
+
+// currentFiles returns the original & the set file of newest history
+// entries, there's one for every output if they were set together.
+// They're kept while pruning the cache.
+func currentFiles() []string {
+	entries, err := history.Read(historyFile())
+	if err != nil || len(entries) == 0 {
+		return []string{}
+	}
+
+	files := []string{}
+	for _, e := range entries {
+		if !e.Time.Equal(entries[0].Time) {
+			break
+		}
+		files = append(files, e.File)
+		if len(e.Set) != 0 {
+			files = append(files, e.Set)
+		}
+	}
+	return files
+}
+
+// totalSize returns the sum of sizes of entries.
+func totalSize(entries []cache.Entry) int64 {
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	return size
+}
+
+// humanSize returns size in bytes with a suffix of K, M or G, these
+// are powers of 1024 like the sizes in config.
+func humanSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry holds information about a cached file. Kind is "json" for
+// responses, "part" for incomplete downloads & "image" for everything
+// else. LastUsed is the time the file was last read by cetus, it's
+// the modification time if cetus never read it.
+type Entry struct {
+	Service  string    `json:"service"`
+	Date     string    `json:"date"`
+	Kind     string    `json:"kind"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// indexFile returns the path to index file, it's stored directly
+// under cache directory so that it's not pruned.
+func indexFile() string {
+	return fmt.Sprintf("%s/%s", GetDir(), "index")
+}
+
+// Touch records in the index that files at paths were used now, files
+// that don't exist or are not in a service directory are ignored.
+func Touch(paths ...string) error {
+	idx := readIndex()
+	for _, p := range paths {
+		if filepath.Dir(filepath.Dir(p)) != filepath.Clean(GetDir()) {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		e := newEntry(p, info)
+		e.LastUsed = time.Now()
+		idx[p] = e
+	}
+
+	entries := []Entry{}
+	for _, e := range idx {
+		entries = append(entries, e)
+	}
+	return writeIndex(entries)
+}
+
+// Entries returns every file in service directories sorted by
+// service, date & path. Last use recorded in the index is added to
+// them & the index is updated to match the files on disk.
+func Entries() ([]Entry, error) {
+	files, err := list()
+	if err != nil {
+		return []Entry{}, err
+	}
+
+	idx := readIndex()
+	entries := []Entry{}
+	for _, f := range files {
+		e := newEntry(f.path, f.info)
+		if old, ok := idx[f.path]; ok && old.LastUsed.After(e.LastUsed) {
+			e.LastUsed = old.LastUsed
+		}
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.Path < b.Path
+	})
+	return entries, writeIndex(entries)
+}
+
+// Clear removes every file in service directories along with the
+// index. Files directly under GetDir() other than the index hold state
+// & are not removed.
+func Clear() error {
+	entries, err := Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err = os.Remove(e.Path)
+		if err != nil {
+			return fmt.Errorf("%s%s\n%s",
+				"index.go: failed to remove file: ", e.Path,
+				err.Error())
+		}
+	}
+
+	err = os.Remove(indexFile())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s%s\n%s",
+			"index.go: failed to remove file: ", indexFile(),
+			err.Error())
+	}
+	return nil
+}
+
+// newEntry returns the entry for file at path. Service is the name of
+// directory & date is taken from the start of filename, files are
+// named <date>.json & <date>-<hash>.<ext>.
+func newEntry(path string, info os.FileInfo) Entry {
+	e := Entry{
+		Service:  filepath.Base(filepath.Dir(path)),
+		Kind:     "image",
+		Path:     path,
+		Size:     info.Size(),
+		LastUsed: info.ModTime(),
+	}
+
+	switch filepath.Ext(path) {
+	case ".json":
+		e.Kind = "json"
+	case ".part":
+		e.Kind = "part"
+	}
+
+	name := info.Name()
+	if len(name) >= 10 {
+		if _, err := time.Parse("2006-01-02", name[:10]); err == nil {
+			e.Date = name[:10]
+		}
+	}
+	if len(e.Date) == 0 && e.Kind == "json" {
+		e.Date = strings.TrimSuffix(name, ".json")
+	}
+	return e
+}
+
+// readIndex returns the entries in index file mapped by path. Index
+// not existing or being invalid is not an error, it's rebuilt from the
+// files on disk.
+func readIndex() map[string]Entry {
+	idx := make(map[string]Entry)
+
+	data, err := ioutil.ReadFile(indexFile())
+	if err != nil {
+		return idx
+	}
+	entries := []Entry{}
+	if json.Unmarshal(data, &entries) != nil {
+		return idx
+	}
+	for _, e := range entries {
+		idx[e.Path] = e
+	}
+	return idx
+}
+
+// writeIndex writes entries to the index file, it's written to a
+// temporary file & renamed so that the index is never incomplete.
+func writeIndex(entries []Entry) error {
+	entries = append([]Entry{}, entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"index.go: failed to marshal index",
+			err.Error())
+	}
+
+	os.MkdirAll(GetDir(), os.ModePerm)
+	tmp := indexFile() + ".tmp"
+	err = ioutil.WriteFile(tmp, append(out, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"index.go: failed to write file: ", tmp,
+			err.Error())
+	}
+	err = os.Rename(tmp, indexFile())
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"index.go: failed to rename file: ", tmp,
+			err.Error())
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPruneLastUsed tests if files used recently are kept while
+// pruning even if they were modified earlier.
+func TestPruneLastUsed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("CETUS_CACHE_DIR", dir)
+	defer os.Unsetenv("CETUS_CACHE_DIR")
+
+	svc := filepath.Join(GetDir(), "apod")
+	os.MkdirAll(svc, os.ModePerm)
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"2020-01-01.json", "2020-01-02.json"} {
+		file := filepath.Join(svc, name)
+		err = ioutil.WriteFile(file, []byte("{}"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(file, old, old)
+	}
+
+	used := filepath.Join(svc, "2020-01-01.json")
+	err = Touch(used)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Prune(24*time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != used || entries[0].Date != "2020-01-01" {
+		t.Errorf("Entries returned %v, want only %s.", entries, used)
+	}
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// file holds information about a cached file.
+type file struct {
+	path string
+	info os.FileInfo
+}
+
+// Prune removes cached files not used for maxAge & then removes the
+// least recently used files until the size of cache is less than
+// maxSize. Limits that are 0 are ignored. Only files in service
+// directories are considered, files directly under GetDir() hold state
+// & are never removed. Files in keep are never removed, they should be
+// the current background.
+func Prune(maxAge time.Duration, maxSize int64, keep ...string) error {
+	kept := map[string]bool{}
+	for _, k := range keep {
+		kept[k] = true
+	}
+
+	entries, err := Entries()
+	if err != nil {
+		return err
+	}
+
+	// Sort entries by last use, most recent first.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	var size int64
+	left := []Entry{}
+	for _, e := range entries {
+		if kept[e.Path] {
+			size += e.Size
+			left = append(left, e)
+			continue
+		}
+
+		old := maxAge > 0 && time.Since(e.LastUsed) > maxAge
+		big := maxSize > 0 && size+e.Size > maxSize
+		if !old && !big {
+			size += e.Size
+			left = append(left, e)
+			continue
+		}
+
+		err = os.Remove(e.Path)
+		if err != nil {
+			writeIndex(left)
+			return fmt.Errorf("%s%s\n%s",
+				"prune.go: failed to remove file: ", e.Path,
+				err.Error())
+		}
+	}
+	return writeIndex(left)
+}
+
+// list returns every file in service directories.
+func list() ([]file, error) {
+	files := []file{}
+
+	dirs, err := ioutil.ReadDir(GetDir())
+	if os.IsNotExist(err) {
+		return files, nil
+	} else if err != nil {
+		return files, fmt.Errorf("%s\n%s",
+			"prune.go: failed to read cache dir",
+			err.Error())
+	}
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(GetDir(), d.Name())
+
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return files, fmt.Errorf("%s%s\n%s",
+				"prune.go: failed to read dir: ", dir,
+				err.Error())
+		}
+		for _, e := range entries {
+			if e.Mode().IsRegular() {
+				files = append(files, file{filepath.Join(dir, e.Name()), e})
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,194 @@
+// Package config manages cetus configuration. Values are taken from
+// environment variables, config file & built-in defaults in that
+// order, flags override these values in package main.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting describes a configuration key.
+type Setting struct {
+	Key     string // key in config file, "section.key"
+	Env     string // environment variable, may be empty
+	Default string
+	Desc    string
+}
+
+// Value holds the effective value of a setting & where it came from.
+type Value struct {
+	Value  string
+	Origin string
+}
+
+// Config holds the effective value of every setting.
+type Config struct {
+	File     string
+	Found    bool
+	settings []Setting
+	values   map[string]Value
+}
+
+// Load resolves settings from env, file & defaults. File not existing
+// is not an error, every other error is returned & so are keys in file
+// that don't belong to any setting. Config is returned even on error
+// with values that could be resolved so that it can be inspected.
+func Load(file string, settings []Setting) (*Config, error) {
+	c := &Config{
+		File:     file,
+		settings: settings,
+		values:   make(map[string]Value),
+	}
+
+	var loadErr error
+	fileValues := make(map[string]string)
+	f, err := os.Open(file)
+	if err == nil {
+		defer f.Close()
+		c.Found = true
+
+		fileValues, err = Parse(f)
+		if err != nil {
+			fileValues = make(map[string]string)
+			loadErr = fmt.Errorf("%s: %s", file, err.Error())
+		}
+	} else if !os.IsNotExist(err) {
+		loadErr = fmt.Errorf("%s%s\n%s",
+			"config.go: failed to open config: ", file,
+			err.Error())
+	}
+
+	known := make(map[string]bool)
+	for _, s := range settings {
+		known[s.Key] = true
+
+		c.values[s.Key] = Value{s.Default, "default"}
+		if v, exists := fileValues[s.Key]; exists {
+			c.values[s.Key] = Value{v, "config"}
+		}
+
+		// We use os.LookupEnv instead of using os.GetEnv and
+		// checking if the length equals 0 because environment
+		// variable can be set and be of length 0. User
+		// could've set key="" which means the variable was set
+		// but the length is 0, this can be used to override
+		// the value in config file.
+		if len(s.Env) == 0 {
+			continue
+		}
+		if v, exists := os.LookupEnv(s.Env); exists {
+			c.values[s.Key] = Value{v, "env " + s.Env}
+		}
+	}
+
+	for k := range fileValues {
+		if !known[k] && loadErr == nil {
+			loadErr = fmt.Errorf("%s: unknown key: %s", file, k)
+		}
+	}
+	return c, loadErr
+}
+
+// Settings returns the settings that c was loaded with.
+func (c *Config) Settings() []Setting {
+	return c.settings
+}
+
+// Value returns the value of key along with its origin.
+func (c *Config) Value(key string) Value {
+	return c.values[key]
+}
+
+// Get returns the value of key.
+func (c *Config) Get(key string) string {
+	return c.values[key].Value
+}
+
+// Set overrides the value of key, this is used for flags.
+func (c *Config) Set(key, value, origin string) {
+	c.values[key] = Value{value, origin}
+}
+
+// Bool returns the value of key parsed as bool.
+func (c *Config) Bool(key string) (bool, error) {
+	v := c.values[key]
+	b, err := strconv.ParseBool(v.Value)
+	if err != nil {
+		err = fmt.Errorf("config.go: %s (%s): invalid bool: %q",
+			key, v.Origin, v.Value)
+	}
+	return b, err
+}
+
+// Int returns the value of key parsed as int.
+func (c *Config) Int(key string) (int, error) {
+	v := c.values[key]
+	i, err := strconv.Atoi(v.Value)
+	if err != nil {
+		err = fmt.Errorf("config.go: %s (%s): invalid integer: %q",
+			key, v.Origin, v.Value)
+	}
+	return i, err
+}
+
+// Float returns the value of key parsed as float64.
+func (c *Config) Float(key string) (float64, error) {
+	v := c.values[key]
+	f, err := strconv.ParseFloat(v.Value, 64)
+	if err != nil {
+		err = fmt.Errorf("config.go: %s (%s): invalid number: %q",
+			key, v.Origin, v.Value)
+	}
+	return f, err
+}
+
+// Duration returns the value of key parsed as time.Duration. Along
+// with the units supported by time.ParseDuration, "d" can be used for
+// days, it can't be combined with other units.
+func (c *Config) Duration(key string) (time.Duration, error) {
+	v := c.values[key]
+
+	var d time.Duration
+	var err error
+	if strings.HasSuffix(v.Value, "d") {
+		var days int64
+		days, err = strconv.ParseInt(strings.TrimSuffix(v.Value, "d"), 10, 64)
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(v.Value)
+	}
+	if err != nil {
+		err = fmt.Errorf("config.go: %s (%s): invalid duration: %q",
+			key, v.Origin, v.Value)
+	}
+	return d, err
+}
+
+// Size returns the value of key parsed as size in bytes. Value may
+// have a suffix of K, M or G, these are powers of 1024.
+func (c *Config) Size(key string) (int64, error) {
+	v := c.values[key]
+
+	num := strings.ToUpper(strings.TrimSpace(v.Value))
+	mul := int64(1)
+	switch {
+	case strings.HasSuffix(num, "K"):
+		mul = 1 << 10
+	case strings.HasSuffix(num, "M"):
+		mul = 1 << 20
+	case strings.HasSuffix(num, "G"):
+		mul = 1 << 30
+	}
+	num = strings.TrimRight(num, "KMG")
+
+	size, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("config.go: %s (%s): invalid size: %q",
+			key, v.Origin, v.Value)
+	}
+	return size * mul, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadUnknownKey tests if Load returns the resolved values along
+// with the error when config has an unknown key.
+func TestLoadUnknownKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.ini")
+	err = ioutil.WriteFile(file, []byte("[cetus]\nservice = bpod\nbogus = 1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(file, []Setting{
+		{Key: "cetus.service"},
+		{Key: "cetus.timeout", Default: "30s"},
+	})
+	if err == nil {
+		t.Errorf("Load didn't return an error on unknown key.")
+	}
+	if c.Get("cetus.service") != "bpod" || c.Get("cetus.timeout") != "30s" {
+		t.Errorf("Load returned %q & %q, want values from file & defaults.",
+			c.Get("cetus.service"), c.Get("cetus.timeout"))
+	}
+}
@@ -0,0 +1,30 @@
+// +build darwin
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// File returns the path to cetus config file. Check if the user has
+// set CETUS_CONFIG, if not then use the default cetus config
+// directory.
+func File() string {
+	file := os.Getenv("CETUS_CONFIG")
+	if len(file) != 0 {
+		return file
+	}
+
+	return fmt.Sprintf("%s/%s", Dir(), "config.ini")
+}
+
+// Dir returns cetus config directory, on macOS it is $HOME/Library/
+// Application Support/cetus.
+func Dir() string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		os.Getenv("HOME"),
+		"Library",
+		"Application Support",
+		"cetus")
+}
@@ -0,0 +1,32 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// File returns the path to cetus config file. Check if the user has
+// set CETUS_CONFIG, if not then check if XDG_CONFIG_HOME is set & if
+// that is not set then assume it to be the default value which is
+// $HOME/.config according to XDG Base Directory Specification.
+func File() string {
+	file := os.Getenv("CETUS_CONFIG")
+	if len(file) != 0 {
+		return file
+	}
+
+	return fmt.Sprintf("%s/%s", Dir(), "config.ini")
+}
+
+// Dir returns cetus config directory.
+func Dir() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if len(configDir) == 0 {
+		configDir = fmt.Sprintf("%s/%s", os.Getenv("HOME"),
+			".config")
+	}
+
+	return fmt.Sprintf("%s/%s", configDir, "cetus")
+}
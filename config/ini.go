@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse reads an ini file from r & returns the values in a map, keys
+// are of the form "section.key". Keys that are not under any section
+// are put under "cetus" section. Lines starting with '#' or ';' are
+// comments, values may be quoted.
+func Parse(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	section := "cetus"
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0, line[0] == '#', line[0] == ';':
+			continue
+
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return values, fmt.Errorf("ini.go: line %d: invalid section: %s",
+					n, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+
+		default:
+			idx := strings.Index(line, "=")
+			if idx == -1 {
+				return values, fmt.Errorf("ini.go: line %d: expected key = value: %s",
+					n, line)
+			}
+			key := strings.TrimSpace(line[:idx])
+			val := strings.TrimSpace(line[idx+1:])
+
+			if len(val) > 1 && val[0] == '"' {
+				unquoted, err := strconv.Unquote(val)
+				if err != nil {
+					return values, fmt.Errorf("ini.go: line %d: invalid quoted value: %s",
+						n, val)
+				}
+				val = unquoted
+			}
+			values[fmt.Sprintf("%s.%s", section, key)] = val
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"ini.go: failed to read config",
+			err.Error())
+	}
+	return values, err
+}
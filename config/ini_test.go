@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParse tests if Parse handles sections, comments & quoted values.
+func TestParse(t *testing.T) {
+	ini := `
+service = apod
+# comment
+[apod]
+key = "DEMO KEY"
+; comment
+api=https://api.nasa.gov/planetary/apod
+`
+	values, err := Parse(strings.NewReader(ini))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"cetus.service": "apod",
+		"apod.key":      "DEMO KEY",
+		"apod.api":      "https://api.nasa.gov/planetary/apod",
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s is incorrect, got %q, want %q.", k, values[k], v)
+		}
+	}
+
+	_, err = Parse(strings.NewReader("[apod\nkey = x"))
+	if err == nil {
+		t.Errorf("Parse didn't return an error for invalid section.")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+var (
+	daemonAt    string
+	daemonEvery time.Duration
+)
+
+// maxSleep is the longest the daemon sleeps before checking the wall
+// clock again. time.Sleep uses the monotonic clock which stops while
+// the system is suspended, without this a run would be late by the
+// time spent in suspend.
+const maxSleep = time.Minute
+
+// execDaemon runs the set pipeline for services on a schedule, it
+// never returns. Background is set once when the daemon starts & then
+// either daily at daemonAt (local time) or every daemonEvery. Failed
+// runs are retried with backoff until the next scheduled run.
+func execDaemon(services []source.Service) {
+	if (len(daemonAt) == 0) == (daemonEvery == 0) {
+		log.Fatal("daemon.go: pass either -at or -every")
+	}
+
+	var at time.Time
+	if len(daemonAt) != 0 {
+		at, err = time.Parse("15:04", daemonAt)
+		if err != nil {
+			log.Fatalf("daemon.go: -at must be of format HH:MM: %q", daemonAt)
+		}
+	}
+
+	for {
+		// Monotonic clock reading is stripped so that times are
+		// compared by the wall clock.
+		now := time.Now().Round(0)
+		next := now.Add(daemonEvery)
+		if daemonEvery == 0 {
+			next = nextDaily(now, at.Hour(), at.Minute())
+		}
+
+		runWithRetry(services, next)
+
+		log.Printf("daemon.go: next run at %s\n",
+			next.Format("2006-01-02 15:04:05"))
+		sleepUntil(next)
+	}
+}
+
+// runWithRetry runs the set pipeline for services, on failure it is
+// retried with exponential backoff starting at 30 seconds & capped at
+// 30 minutes. It gives up when the next retry would be after
+// deadline, the next scheduled run will try again.
+func runWithRetry(services []source.Service, deadline time.Time) {
+	backoff := 30 * time.Second
+	for {
+		err := execSet(services)
+		if err == nil {
+			return
+		}
+		log.Println(err)
+
+		if time.Now().Add(backoff).After(deadline) {
+			log.Println("daemon.go: giving up until next scheduled run")
+			return
+		}
+		log.Printf("daemon.go: retrying in %s\n", backoff)
+		sleepUntil(time.Now().Add(backoff))
+
+		backoff *= 2
+		if backoff > 30*time.Minute {
+			backoff = 30 * time.Minute
+		}
+	}
+}
+
+// sleepUntil sleeps until the wall clock reaches t, it sleeps for at
+// most maxSleep at a time & checks the clock again.
+func sleepUntil(t time.Time) {
+	for {
+		d := time.Until(t.Round(0))
+		if d <= 0 {
+			return
+		}
+		if d > maxSleep {
+			d = maxSleep
+		}
+		time.Sleep(d)
+	}
+}
+
+// nextDaily returns the next time after now at hour:min in now's
+// location.
+func nextDaily(now time.Time, hour, min int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(),
+		hour, min, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
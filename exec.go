@@ -0,0 +1,364 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/notification"
+	"tildegit.org/andinus/cetus/request"
+	"tildegit.org/andinus/cetus/source"
+)
+
+// execService runs the pipeline for service s. It gets the response
+// from cache if available, otherwise it's fetched from the source.
+// Then the info is printed/sent as notification & background is set
+// if set is true. Errors are returned instead of exiting because
+// daemon retries on failure.
+func execService(s source.Service, set bool) error {
+	pic, cacheDir, err := getPicture(s)
+	if err != nil {
+		return err
+	}
+
+	// Proceed only if the command was set because if it was fetch
+	// then it's already finished. Image is still downloaded if
+	// the user asked for its palette.
+	if !set {
+		if !paletteOn {
+			return report(pic, cachedImage(pic, cacheDir))
+		}
+		pic, imgFile, err := imageOrCached(s, pic, cacheDir)
+		if err != nil {
+			return err
+		}
+		return report(pic, imgFile)
+	}
+
+	// Try to set background only if the media type is an image.
+	pic, imgFile, err := imageOrCached(s, pic, cacheDir)
+	if err != nil {
+		return err
+	}
+	err = report(pic, imgFile)
+	if err != nil || len(imgFile) == 0 {
+		return err
+	}
+
+	src := imgFile
+	imgFile = fitImage(cacheDir, imgFile)
+	imgFile = overlayImage(cacheDir, imgFile, pic)
+
+	err = background.SetFromFile(imgFile)
+	if err != nil {
+		return err
+	}
+	addHistory(time.Now(), pic, src, imgFile)
+	postSet(pic, imgFile)
+
+	pruneCache(src, imgFile)
+	return nil
+}
+
+// getPicture returns the picture from service s along with the cache
+// directory of s. Info is sent as notification if the user asked for
+// it.
+func getPicture(s source.Service) (source.Picture, string, error) {
+	cacheDir := fmt.Sprintf("%s/%s", cache.GetDir(), s.Name)
+	os.MkdirAll(cacheDir, os.ModePerm)
+
+	q := source.Query{Date: date, Random: random}
+	q.Date, err = s.Source.Date(q)
+	if err != nil {
+		return source.Picture{}, cacheDir, err
+	}
+
+	// Sources that read from the filesystem work as usual in
+	// offline mode, others choose a picture from the cache.
+	_, local := s.Source.(source.Local)
+	var body string
+	if offline && !local {
+		q.Date, body, err = offlineBody(s, cacheDir)
+		if err != nil {
+			return source.Picture{}, cacheDir, err
+		}
+	}
+
+	// Pre fetch hook can stop the run by failing.
+	err = runHook("pre_fetch", []string{
+		"CETUS_SERVICE=" + s.Name,
+		"CETUS_DATE=" + q.Date,
+	})
+	if err != nil {
+		return source.Picture{}, cacheDir, err
+	}
+
+	if len(body) == 0 {
+		body, err = readCache(cacheDir, q.Date)
+		if err != nil {
+			return source.Picture{}, cacheDir, err
+		}
+	}
+	if len(body) == 0 {
+		request.Unreachable = false
+		body, err = s.Source.Fetch(q)
+
+		// Fall back to the cache if the network is down.
+		if err != nil && request.Unreachable && !local {
+			log.Println(err)
+			log.Println("exec.go: network is unreachable, choosing picture from cache")
+			q.Date, body, err = offlineBody(s, cacheDir)
+		}
+		if err != nil {
+			return source.Picture{}, cacheDir, err
+		}
+	}
+
+	if dump {
+		fmt.Println(body)
+	}
+
+	pic, err := s.Source.Parse(body)
+	if err != nil {
+		return pic, cacheDir, err
+	}
+	if len(pic.Date) == 0 {
+		pic.Date = q.Date
+	}
+	writeCache(cacheDir, pic)
+
+	// Send a desktop notification if notify flag was passed.
+	if notify {
+		n := notification.Notif{}
+		n.Title = pic.Title
+		n.Message = fmt.Sprintf("%s\n\n%s", pic.Date, pic.Description)
+		if len(pic.Description) == 0 {
+			n.Message = fmt.Sprintf("%s\n\n%s", pic.Date, pic.Credit)
+		}
+
+		err = n.Notify()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	err = runHook("post_fetch", hookEnv(pic, pic.File))
+	if err != nil {
+		log.Println(err)
+	}
+	return pic, cacheDir, nil
+}
+
+// imageOrCached returns pic along with the path to its image like
+// getImage. If the image can't be downloaded because the network is
+// down then a picture of s whose image is cached is returned instead.
+// Palette of the image is written once it's on disk.
+func imageOrCached(s source.Service, pic source.Picture, cacheDir string) (source.Picture, string, error) {
+	pic, imgFile, err := downloadImage(s, pic, cacheDir)
+	if err == nil && len(imgFile) != 0 {
+		writePalette(imgFile)
+	}
+	return pic, imgFile, err
+}
+
+// downloadImage does the work of imageOrCached.
+func downloadImage(s source.Service, pic source.Picture, cacheDir string) (source.Picture, string, error) {
+	request.Unreachable = false
+	imgFile, err := getImage(pic, cacheDir)
+	if err == nil || !request.Unreachable {
+		return pic, imgFile, err
+	}
+	if _, local := s.Source.(source.Local); local {
+		return pic, imgFile, err
+	}
+
+	log.Println(err)
+	log.Println("exec.go: network is unreachable, choosing picture from cache")
+	d, body, err := offlineBody(s, cacheDir)
+	if err != nil {
+		return pic, "", err
+	}
+	pic, err = s.Source.Parse(body)
+	if err != nil {
+		return pic, "", err
+	}
+	if len(pic.Date) == 0 {
+		pic.Date = d
+	}
+	return pic, cachedImage(pic, cacheDir), nil
+}
+
+// getImage returns the path to image of pic, it's downloaded to
+// cacheDir if the source didn't provide a file. An empty path is
+// returned if the media type is not an image.
+func getImage(pic source.Picture, cacheDir string) (string, error) {
+	if pic.MediaType != "image" {
+		return "", nil
+	}
+	if len(pic.File) != 0 {
+		return pic.File, nil
+	}
+
+	// Check if the file is available locally, if it is then don't
+	// download it again and set it from disk. Downloads are
+	// verified before they're cached so only the header is checked
+	// here, files left broken by earlier versions are removed &
+	// downloaded again.
+	base := imageBase(pic, cacheDir)
+	imgFile := background.Cached(base)
+	if len(imgFile) != 0 && background.Check(imgFile) != nil {
+		log.Printf("exec.go: removing invalid cached image: %s\n", imgFile)
+		os.Remove(imgFile)
+		imgFile = ""
+	}
+	if len(imgFile) == 0 {
+		return background.Download(base, pic.URL)
+	}
+	touchCache(imgFile)
+	return imgFile, nil
+}
+
+// postSet runs post set hook for pic set from file, env is added to
+// its environment. Errors are not returned because the background has
+// already been set.
+func postSet(pic source.Picture, file string, env ...string) {
+	err := runHook("post_set", append(hookEnv(pic, file), env...))
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// imageBase returns the path without extension where image of pic is
+// cached. It's named after the date & hash of url so that it's safe
+// to use as a filename & pictures with the same title don't collide.
+func imageBase(pic source.Picture, cacheDir string) string {
+	sum := sha1.Sum([]byte(pic.URL))
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%x", pic.Date, sum[:4]))
+}
+
+// cachedImage returns the path to image of pic if it's on disk,
+// otherwise an empty string is returned.
+func cachedImage(pic source.Picture, cacheDir string) string {
+	if pic.MediaType != "image" {
+		return ""
+	}
+	if len(pic.File) != 0 {
+		return pic.File
+	}
+	return background.Cached(imageBase(pic, cacheDir))
+}
+
+// pruneCache removes cached files according to the limits set by the
+// user, files in keep are never removed. Errors are not returned
+// because the background has already been set.
+func pruneCache(keep ...string) {
+	maxAge, err := conf.Duration("cache.max_age")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	maxSize, err := conf.Size("cache.max_size")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	err = cache.Prune(maxAge, maxSize, keep...)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// readCache returns the cached body for date, it returns an empty
+// string if date is empty or the body is not cached.
+func readCache(cacheDir, date string) (string, error) {
+	if len(date) == 0 {
+		return "", nil
+	}
+	file := fmt.Sprintf("%s/%s.json", cacheDir, date)
+
+	// Check if the file is available locally, if it is then don't
+	// download it again and get it from disk.
+	_, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		// If file existed then that is handled later, if it
+		// didn't exist then that is handled by the if block.
+		// If we reach here then that means it's Schrödinger's
+		// file & something else went wrong.
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(file)
+
+	// Not being able to read from the cache file is a small error
+	// and the program shouldn't exit but should continue after
+	// printing the log so that the user can investigate it later.
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"exec.go: failed to read file to data: ", file,
+			err.Error())
+		log.Println(err)
+		return "", nil
+	}
+	touchCache(file)
+	return string(data), nil
+}
+
+// touchCache records that files in cache were used so that they're
+// pruned last. Not being able to update the index is not fatal.
+func touchCache(files ...string) {
+	err := cache.Touch(files...)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// writeCache writes pic.Body to the cache so that it can be read
+// later. Sources that don't want the body to be cached leave it
+// empty.
+func writeCache(cacheDir string, pic source.Picture) {
+	if len(pic.Body) == 0 {
+		return
+	}
+	file := fmt.Sprintf("%s/%s.json", cacheDir, pic.Date)
+
+	err := ioutil.WriteFile(file, []byte(pic.Body), 0644)
+
+	// Not being able to write to the cache file is a small error
+	// and the program shouldn't exit but should continue after
+	// printing the log so that the user can investigate it later.
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"exec.go: failed to write body to file: ", file,
+			err.Error())
+		log.Println(err)
+	}
+}
+
+// printPicture prints information about pic, fields that are empty are
+// not printed.
+func printPicture(pic source.Picture) {
+	fmt.Printf("Title: %s\n\n", pic.Title)
+	if len(pic.Credit) != 0 {
+		fmt.Printf("Copyright: %s\n", pic.Credit)
+	}
+	if len(pic.CreditURL) != 0 {
+		fmt.Printf("Copyright Link: %s\n", pic.CreditURL)
+	}
+	fmt.Printf("Date: %s\n\n", pic.Date)
+	if len(pic.MediaType) != 0 {
+		fmt.Printf("Media Type: %s\n", pic.MediaType)
+	}
+	fmt.Printf("URL: %s\n", pic.URL)
+	if len(pic.Description) != 0 {
+		fmt.Printf("\nExplanation: %s\n", pic.Description)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/fav"
+	"tildegit.org/andinus/cetus/history"
+)
+
+// execFav handles fav command. `fav add [N]` adds entry N of history
+// (default 0, the current background) to favorites & `fav list`
+// prints the favorites. Favorites are set with `cetus set fav`.
+func execFav() {
+	if len(os.Args) < 3 {
+		favUsage()
+	}
+
+	switch os.Args[2] {
+	case "add":
+		n := 0
+		if len(os.Args) > 3 {
+			n, err = strconv.Atoi(os.Args[3])
+			if err != nil || n < 0 {
+				fmt.Printf("Invalid entry: %q\n", os.Args[3])
+				favUsage()
+			}
+		}
+
+		entries, err := history.Read(historyFile())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n >= len(entries) {
+			log.Fatalf("fav.go: entry %d doesn't exist, history has %d entries",
+				n, len(entries))
+		}
+
+		e, err := fav.Add(entries[n], cache.GetDir())
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Added to favorites: %s (%s): %s\n", e.Service, e.Date, e.Title)
+
+	case "list":
+		entries, err := fav.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Service\tDate\tTitle")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", e.Service, e.Date, e.Title)
+		}
+		w.Flush()
+
+	default:
+		fmt.Printf("Invalid fav command: %q\n", os.Args[2])
+		favUsage()
+	}
+}
+
+func favUsage() {
+	fmt.Println("Usage: cetus fav add [N]")
+	fmt.Println("       cetus fav list")
+	fmt.Println("       cetus set fav [-random]")
+	os.Exit(1)
+}
@@ -0,0 +1,19 @@
+// +build darwin
+
+package fav
+
+import (
+	"fmt"
+	"os"
+)
+
+// Dir returns cetus favorites directory. Favorites are not stored in
+// cache because cache can be pruned, on macOS they're stored in
+// $HOME/Library/Application Support/cetus/favorites.
+func Dir() string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		os.Getenv("HOME"),
+		"Library",
+		"Application Support",
+		"cetus/favorites")
+}
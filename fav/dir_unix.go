@@ -0,0 +1,23 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package fav
+
+import (
+	"fmt"
+	"os"
+)
+
+// Dir returns cetus favorites directory. Favorites are not stored in
+// cache because cache can be pruned, they're stored in
+// $XDG_DATA_HOME/cetus/favorites & if XDG_DATA_HOME is not set then
+// it's assumed to be $HOME/.local/share according to XDG Base
+// Directory Specification.
+func Dir() string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if len(dataDir) == 0 {
+		dataDir = fmt.Sprintf("%s/%s", os.Getenv("HOME"),
+			".local/share")
+	}
+
+	return fmt.Sprintf("%s/%s", dataDir, "cetus/favorites")
+}
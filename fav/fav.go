@@ -0,0 +1,112 @@
+// Package fav manages favorite backgrounds. Favorites are copied out
+// of the cache along with their cached json so that they're never
+// pruned.
+package fav
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"tildegit.org/andinus/cetus/history"
+)
+
+// Index returns the path to favorites index, it holds an entry for
+// every favorite in the same format as history.
+func Index() string {
+	return fmt.Sprintf("%s/%s", Dir(), "index")
+}
+
+// List returns every favorite, oldest first.
+func List() ([]history.Entry, error) {
+	entries, err := history.Read(Index())
+
+	// history.Read returns newest entry first.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, err
+}
+
+// Add copies the image of e & its cached json (if it exists) from
+// cacheDir to favorites directory & adds it to the index. It returns
+// the entry that was added, File points to the copied image.
+func Add(e history.Entry, cacheDir string) (history.Entry, error) {
+	entries, err := List()
+	if err != nil {
+		return e, err
+	}
+	for _, f := range entries {
+		if f.Service == e.Service && f.Date == e.Date && f.URL == e.URL {
+			return f, fmt.Errorf("fav.go: already a favorite: %s", e.Title)
+		}
+	}
+
+	dir := filepath.Join(Dir(), e.Service)
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return e, fmt.Errorf("%s%s\n%s",
+			"fav.go: failed to create dir: ", dir,
+			err.Error())
+	}
+
+	// Copy is named after the date & hash of the original path
+	// so that images with the same name don't overwrite each
+	// other.
+	sum := sha1.Sum([]byte(e.File))
+	img := filepath.Join(dir, fmt.Sprintf("%s-%x%s",
+		e.Date, sum[:4], filepath.Ext(e.File)))
+	err = copyFile(img, e.File)
+	if err != nil {
+		return e, err
+	}
+	e.File = img
+	e.Set = ""
+
+	// Cached json is copied so that the favorite can be parsed
+	// by its source later, local source doesn't cache json.
+	body := filepath.Join(cacheDir, e.Service, e.Date+".json")
+	if _, err := os.Stat(body); err == nil {
+		err = copyFile(filepath.Join(dir, e.Date+".json"), body)
+		if err != nil {
+			return e, err
+		}
+	}
+
+	err = history.Add(Index(), e)
+	return e, err
+}
+
+// Body returns the path to cached json of favorite e.
+func Body(e history.Entry) string {
+	return filepath.Join(Dir(), e.Service, e.Date+".json")
+}
+
+// copyFile copies src to dst, dst is overwritten if it exists.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"fav.go: failed to open file: ", src,
+			err.Error())
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"fav.go: failed to create file: ", dst,
+			err.Error())
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"fav.go: failed to copy file: ", src,
+			err.Error())
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package fav
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"time"
+
+	"tildegit.org/andinus/cetus/history"
+	"tildegit.org/andinus/cetus/source"
+)
+
+// Source implements source.Source for favorites. Favorites are chosen
+// sequentially, path of the last favorite is saved in State.
+type Source struct {
+	State string
+}
+
+// Local marks Source as local, favorites are read from the favorites
+// directory.
+func (s *Source) Local() {}
+
+// Date returns q.Date after checking that it's valid, it's used to
+// filter favorites in Fetch. An empty string is returned if date was
+// not passed because the favorite is not known until it's chosen.
+func (s *Source) Date(q source.Query) (string, error) {
+	if len(q.Date) == 0 {
+		return "", nil
+	}
+	_, err := time.Parse("2006-01-02", q.Date)
+	if err != nil {
+		return "", fmt.Errorf("source.go: invalid date: %q", q.Date)
+	}
+	return q.Date, nil
+}
+
+// Fetch chooses a favorite & returns its entry as body. If q.Date is
+// set then only favorites of that date are considered. A random
+// favorite is chosen if q.Random is true, otherwise the favorite
+// after the last one is chosen.
+func (s *Source) Fetch(q source.Query) (string, error) {
+	entries, err := List()
+	if err != nil {
+		return "", err
+	}
+
+	list := []history.Entry{}
+	for _, e := range entries {
+		if len(q.Date) == 0 || e.Date == q.Date {
+			list = append(list, e)
+		}
+	}
+	if len(list) == 0 {
+		return "", fmt.Errorf("source.go: no favorite found, add one with `cetus fav add`")
+	}
+
+	e := list[0]
+	if q.Random {
+		e = list[rand.Intn(len(list))]
+	} else {
+		// Not being able to read the state file is not an
+		// error, it won't exist on first run.
+		last, _ := ioutil.ReadFile(s.State)
+		for i, f := range list {
+			if f.File == strings.TrimSpace(string(last)) {
+				e = list[(i+1)%len(list)]
+			}
+		}
+
+		err = ioutil.WriteFile(s.State, []byte(e.File+"\n"), 0644)
+		if err != nil {
+			return "", fmt.Errorf("%s%s\n%s",
+				"source.go: failed to write state to file: ", s.State,
+				err.Error())
+		}
+	}
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("%s\n%s",
+			"source.go: failed to marshal entry",
+			err.Error())
+	}
+	return string(out), nil
+}
+
+// Parse converts body to source.Picture. If json of the favorite was
+// saved then it's parsed by the source it came from, this way
+// favorites have the same information as the original picture.
+// Otherwise the information in the index is used. Body is not
+// cached.
+func (s *Source) Parse(body string) (source.Picture, error) {
+	pic := source.Picture{}
+
+	e := history.Entry{}
+	err := json.Unmarshal([]byte(body), &e)
+	if err != nil {
+		return pic, fmt.Errorf("source.go: unmarshalling json failed\n%s",
+			err.Error())
+	}
+
+	pic.Service = e.Service
+	pic.Date = e.Date
+	pic.Title = e.Title
+	pic.MediaType = "image"
+	pic.URL = e.URL
+
+	data, err := ioutil.ReadFile(Body(e))
+	if err == nil {
+		if svc, err := source.Get(e.Service); err == nil {
+			if p, err := svc.Source.Parse(string(data)); err == nil {
+				pic = p
+			}
+		}
+	}
+
+	if len(pic.Date) == 0 {
+		pic.Date = e.Date
+	}
+	pic.File = e.File
+	pic.Body = ""
+	return pic, nil
+}
@@ -0,0 +1,68 @@
+package fit
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+
+	// Decoders for formats that the image package supports.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// File fits image in src to w x h with mode & saves it to dst as
+// jpeg.
+func File(dst, src string, w, h int, mode string, bg color.Color) error {
+	img, err := Load(src)
+	if err != nil {
+		return err
+	}
+
+	fitted, err := Apply(img, w, h, mode, bg)
+	if err != nil {
+		return err
+	}
+	return Save(dst, fitted)
+}
+
+// Load decodes the image in src.
+func Load(src string) (image.Image, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s\n%s",
+			"file.go: failed to open file: ", src,
+			err.Error())
+	}
+	defer in.Close()
+
+	img, _, err := image.Decode(in)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s\n%s",
+			"file.go: failed to decode image: ", src,
+			err.Error())
+	}
+	return img, nil
+}
+
+// Save encodes img as jpeg & saves it to dst, dst is removed if
+// encoding fails.
+func Save(dst string, img image.Image) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"file.go: failed to create file: ", dst,
+			err.Error())
+	}
+
+	err = jpeg.Encode(out, img, &jpeg.Options{Quality: 92})
+	if err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("%s%s\n%s",
+			"file.go: failed to encode image: ", dst,
+			err.Error())
+	}
+	return out.Close()
+}
@@ -0,0 +1,94 @@
+// Package fit scales images to fit the screen.
+package fit
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Fill scales img to fill w x h while keeping the aspect ratio, parts
+// of img that don't fit are cropped equally from both sides. This is
+// the same as feh's --bg-fill.
+func Fill(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// Crop the source to the aspect ratio of destination.
+	crop := b
+	if sw*h > sh*w {
+		cw := sh * w / h
+		crop.Min.X = b.Min.X + (sw-cw)/2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := sw * h / w
+		crop.Min.Y = b.Min.Y + (sh-ch)/2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+
+	return Scale(img, crop, w, h)
+}
+
+// Scale scales the part r of img to w x h with bilinear
+// interpolation, aspect ratio is not kept.
+func Scale(img image.Image, r image.Rectangle, w, h int) *image.RGBA {
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 || r.Empty() {
+		return dst
+	}
+
+	// Scale factor in 16.16 fixed point.
+	xs := (r.Dx() << 16) / w
+	ys := (r.Dy() << 16) / h
+
+	for y := 0; y < h; y++ {
+		// Sample from the center of destination pixel.
+		fy := (y*ys + ys/2) - 1<<15
+		if fy < 0 {
+			fy = 0
+		}
+		y0 := r.Min.Y + fy>>16
+		y1 := y0 + 1
+		if y1 >= r.Max.Y {
+			y1 = r.Max.Y - 1
+		}
+		wy := uint32(fy & 0xffff)
+
+		for x := 0; x < w; x++ {
+			fx := (x*xs + xs/2) - 1<<15
+			if fx < 0 {
+				fx = 0
+			}
+			x0 := r.Min.X + fx>>16
+			x1 := x0 + 1
+			if x1 >= r.Max.X {
+				x1 = r.Max.X - 1
+			}
+			wx := uint32(fx & 0xffff)
+
+			p00 := src.PixOffset(x0, y0)
+			p01 := src.PixOffset(x1, y0)
+			p10 := src.PixOffset(x0, y1)
+			p11 := src.PixOffset(x1, y1)
+			d := dst.PixOffset(x, y)
+
+			for c := 0; c < 4; c++ {
+				top := uint32(src.Pix[p00+c])*(0x10000-wx) + uint32(src.Pix[p01+c])*wx
+				bot := uint32(src.Pix[p10+c])*(0x10000-wx) + uint32(src.Pix[p11+c])*wx
+				v := (uint64(top)*uint64(0x10000-wy) + uint64(bot)*uint64(wy)) >> 32
+				dst.Pix[d+c] = uint8(v)
+			}
+		}
+	}
+	return dst
+}
+
+// toRGBA returns img as *image.RGBA, it's converted only if required.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,60 @@
+package fit
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestFill tests if Fill crops the image equally from both sides. The
+// source is red on the sides & blue in the middle, it's wider than
+// destination so only blue should remain.
+func TestFill(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= 100 && x < 200 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := Fill(src, 50, 50)
+	if dst.Bounds().Dx() != 50 || dst.Bounds().Dy() != 50 {
+		t.Fatalf("Size is incorrect, got %v, want 50x50.", dst.Bounds())
+	}
+	for _, p := range []image.Point{{0, 0}, {49, 49}, {25, 25}} {
+		c := dst.RGBAAt(p.X, p.Y)
+		if c.B != 255 || c.R != 0 {
+			t.Errorf("Pixel at %v is %v, want blue.", p, c)
+		}
+	}
+}
+
+// TestSmart tests if Smart keeps the region with most detail. The
+// source is plain except for a striped area on the right.
+func TestSmart(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{128, 128, 128, 255}
+			if x >= 200 && x%2 == 0 {
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := Smart(src, 100, 100)
+	white := 0
+	for x := 0; x < 100; x++ {
+		if dst.RGBAAt(x, 50).R == 255 {
+			white++
+		}
+	}
+	if white < 45 {
+		t.Errorf("Smart kept %d white columns, want the striped region.", white)
+	}
+}
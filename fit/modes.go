@@ -0,0 +1,160 @@
+package fit
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+// Modes holds every mode supported by Apply.
+var Modes = []string{"fill", "fit", "center", "smart"}
+
+// Apply returns img fitted to w x h with mode, bg is the colour used
+// for areas that img doesn't cover.
+func Apply(img image.Image, w, h int, mode string, bg color.Color) (*image.RGBA, error) {
+	switch mode {
+	case "fill":
+		return Fill(img, w, h), nil
+	case "fit":
+		return Fit(img, w, h, bg), nil
+	case "center":
+		return Center(img, w, h, bg), nil
+	case "smart":
+		return Smart(img, w, h), nil
+	}
+	return nil, fmt.Errorf("modes.go: invalid mode: %q, valid modes: %s",
+		mode, strings.Join(Modes, ", "))
+}
+
+// Fit scales img to fit inside w x h while keeping the aspect ratio,
+// the rest is filled with bg.
+func Fit(img image.Image, w, h int, bg color.Color) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	fw, fh := w, sh*w/sw
+	if fh > h {
+		fw, fh = sw*h/sh, h
+	}
+
+	dst := canvas(w, h, bg)
+	scaled := Scale(img, b, fw, fh)
+	off := image.Pt((w-fw)/2, (h-fh)/2)
+	draw.Draw(dst, scaled.Bounds().Add(off), scaled, image.Point{}, draw.Src)
+	return dst
+}
+
+// Center draws img at the center of w x h without scaling, the rest
+// is filled with bg. img is cropped if it's larger.
+func Center(img image.Image, w, h int, bg color.Color) *image.RGBA {
+	b := img.Bounds()
+
+	dst := canvas(w, h, bg)
+	off := image.Pt((w-b.Dx())/2, (h-b.Dy())/2)
+	draw.Draw(dst, b.Sub(b.Min).Add(off), img, b.Min, draw.Over)
+	return dst
+}
+
+// Smart crops img to the aspect ratio of w x h like Fill but instead
+// of cropping equally from both sides the region with most detail is
+// kept. Detail is measured as the sum of differences between
+// neighbouring pixels.
+func Smart(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// Only one axis is cropped, the other one is kept whole.
+	horizontal := sw*h > sh*w
+	size := sh * w / h
+	if !horizontal {
+		size = sw * h / w
+	}
+
+	energy := detail(img, horizontal)
+
+	// Find the window of size with maximum energy using a
+	// sliding sum.
+	best, sum, max := 0, 0, -1
+	for i := range energy {
+		sum += energy[i]
+		if i >= size {
+			sum -= energy[i-size]
+		}
+		if i >= size-1 && sum > max {
+			max, best = sum, i-size+1
+		}
+	}
+
+	crop := b
+	if horizontal {
+		crop.Min.X = b.Min.X + best
+		crop.Max.X = crop.Min.X + size
+	} else {
+		crop.Min.Y = b.Min.Y + best
+		crop.Max.Y = crop.Min.Y + size
+	}
+	return Scale(img, crop, w, h)
+}
+
+// detail returns the detail of every column (or row if horizontal is
+// false) of img. Every other pixel is sampled along the axis that is
+// summed, this is good enough & twice as fast.
+func detail(img image.Image, horizontal bool) []int {
+	src := toRGBA(img)
+	b := src.Bounds()
+
+	lum := func(x, y int) int {
+		off := src.PixOffset(x, y)
+		return (299*int(src.Pix[off]) + 587*int(src.Pix[off+1]) +
+			114*int(src.Pix[off+2])) / 1000
+	}
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	n := b.Dy()
+	if horizontal {
+		n = b.Dx()
+	}
+	energy := make([]int, n)
+
+	xStep, yStep := 2, 1
+	if horizontal {
+		xStep, yStep = 1, 2
+	}
+	for y := b.Min.Y + 1; y < b.Max.Y; y += yStep {
+		for x := b.Min.X + 1; x < b.Max.X; x += xStep {
+			l := lum(x, y)
+			e := abs(l-lum(x-1, y)) + abs(l-lum(x, y-1))
+			if horizontal {
+				energy[x-b.Min.X] += e
+			} else {
+				energy[y-b.Min.Y] += e
+			}
+		}
+	}
+	return energy
+}
+
+// canvas returns a new image of w x h filled with bg.
+func canvas(w, h int, bg color.Color) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	return dst
+}
+
+// ParseColor parses colour in the form #rrggbb.
+func ParseColor(s string) (color.Color, error) {
+	s = strings.TrimPrefix(s, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 6 {
+		return nil, fmt.Errorf("modes.go: invalid colour: %q, expected #rrggbb", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
@@ -0,0 +1,29 @@
+// +build darwin
+
+package fit
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// Screen returns the resolution of the main display from
+// system_profiler.
+func Screen() (int, int, error) {
+	out, err := exec.Command("system_profiler", "SPDisplaysDataType").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s\n%s",
+			"screen_darwin.go: failed to get resolution with system_profiler",
+			err.Error())
+	}
+
+	m := regexp.MustCompile(`Resolution: (\d+) x (\d+)`).FindStringSubmatch(string(out))
+	if m == nil {
+		return 0, 0, fmt.Errorf("screen_darwin.go: resolution not found in system_profiler output")
+	}
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	return w, h, nil
+}
@@ -0,0 +1,54 @@
+// +build linux netbsd openbsd freebsd dragonfly
+
+package fit
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// Screen returns the resolution of the largest output. On wayland
+// it's taken from the current modes listed by wlr-randr, otherwise
+// from the monitors listed by xrandr. Size of the whole X screen is
+// not used because it spans every output.
+func Screen() (int, int, error) {
+	if len(os.Getenv("WAYLAND_DISPLAY")) != 0 {
+		out, err := exec.Command("wlr-randr").Output()
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s\n%s",
+				"screen_unix.go: failed to get resolution with wlr-randr",
+				err.Error())
+		}
+		re := regexp.MustCompile(`(\d+)x(\d+) px[^\n]*current`)
+		return largest(re, string(out), "wlr-randr")
+	}
+
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s\n%s",
+			"screen_unix.go: failed to get resolution with xrandr",
+			err.Error())
+	}
+	re := regexp.MustCompile(`(\d+)/\d+x(\d+)/\d+\+-?\d+\+-?\d+`)
+	return largest(re, string(out), "xrandr")
+}
+
+// largest returns width & height of the largest resolution matched by
+// re in out.
+func largest(re *regexp.Regexp, out, cmd string) (int, int, error) {
+	var w, h int
+	for _, m := range re.FindAllStringSubmatch(out, -1) {
+		mw, _ := strconv.Atoi(m[1])
+		mh, _ := strconv.Atoi(m[2])
+		if mw*mh > w*h {
+			w, h = mw, mh
+		}
+	}
+	if w == 0 || h == 0 {
+		return 0, 0, fmt.Errorf("screen_unix.go: resolution not found in %s output", cmd)
+	}
+	return w, h, nil
+}
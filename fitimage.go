@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tildegit.org/andinus/cetus/fit"
+)
+
+// fitMode is the mode used to fit the image to the screen, "none"
+// disables fitting.
+var fitMode string
+
+// fitImage returns the path to a copy of file fitted to the screen,
+// the copy is saved in cacheDir & reused if it exists. file is
+// returned as is if fitMode is none or fitting fails because the
+// backend can still set it.
+func fitImage(cacheDir, file string) string {
+	if len(fitMode) == 0 || fitMode == "none" {
+		return file
+	}
+
+	w, h, err := screenSize()
+	if err != nil {
+		log.Println(err)
+		return file
+	}
+	return fitImageTo(cacheDir, file, w, h, fitMode)
+}
+
+// checkFitMode returns an error if mode is not none or one of
+// fit.Modes, origin is where the mode came from.
+func checkFitMode(mode, origin string) error {
+	if len(mode) == 0 || mode == "none" {
+		return nil
+	}
+	for _, m := range fit.Modes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("fitimage.go: invalid fit mode (%s): %q, valid modes: none, %s",
+		origin, mode, strings.Join(fit.Modes, ", "))
+}
+
+// fitImageTo returns the path to a copy of file fitted to w x h with
+// mode, the copy is saved in cacheDir & reused if it exists. file is
+// returned as is if mode is none or fitting fails. Name of the copy
+// starts with the name of file so that it keeps the date, hash of the
+// path, modification time & fit.color is added so that the copy isn't
+// reused after any of them change.
+func fitImageTo(cacheDir, file string, w, h int, mode string) string {
+	if len(mode) == 0 || mode == "none" {
+		return file
+	}
+
+	bg, err := fit.ParseColor(conf.Get("fit.color"))
+	if err != nil {
+		log.Println(err)
+		return file
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		log.Println(err)
+		return file
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s\n%d\n%s",
+		file, info.ModTime().UnixNano(), conf.Get("fit.color"))))
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	out := filepath.Join(cacheDir, fmt.Sprintf("%s-%x-%dx%d-%s.jpg",
+		name, sum[:4], w, h, mode))
+	if _, err := os.Stat(out); err == nil {
+		return out
+	}
+
+	err = fit.File(out, file, w, h, mode, bg)
+	if err != nil {
+		log.Println(err)
+		return file
+	}
+	return out
+}
+
+// screenSize returns the resolution set by the user, if it's not set
+// then it's detected.
+func screenSize() (int, int, error) {
+	res := conf.Value("fit.resolution")
+	if len(res.Value) == 0 {
+		return fit.Screen()
+	}
+
+	var w, h int
+	_, err := fmt.Sscanf(res.Value, "%dx%d", &w, &h)
+	if err != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("fitimage.go: fit.resolution (%s): invalid resolution: %q, expected WxH",
+			res.Origin, res.Value)
+	}
+	return w, h, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/history"
+	"tildegit.org/andinus/cetus/source"
+)
+
+// historyFile returns the path to history file, it's stored directly
+// under cache directory so that it's not pruned.
+func historyFile() string {
+	return fmt.Sprintf("%s/%s", cache.GetDir(), "history")
+}
+
+// addHistory records pic set at t in history, file must be the image
+// before fitting & overlay & set is the copy that was set. Fitting &
+// overlay are done again when the entry is set so that the current
+// settings are used. Not being able to record history is not fatal
+// because background has already been set.
+func addHistory(t time.Time, pic source.Picture, file, set string) {
+	os.MkdirAll(cache.GetDir(), os.ModePerm)
+
+	e := history.Entry{
+		Time:    t,
+		Service: pic.Service,
+		Date:    pic.Date,
+		Title:   pic.Title,
+		File:    file,
+		URL:     pic.URL,
+	}
+	if set != file {
+		e.Set = set
+	}
+	err := history.Add(historyFile(), e)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// execHistory prints the history, newest entry first. Entry 0 is the
+// current background.
+func execHistory() {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	num := fs.Int("n", 20, "Number of entries to print, 0 prints all")
+	fs.Parse(os.Args[2:])
+
+	migrateCache()
+
+	entries, err := history.Read(historyFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *num > 0 && len(entries) > *num {
+		entries = entries[:*num]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "N\tTime\tService\tDate\tTitle")
+	for i, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i,
+			e.Time.Local().Format("2006-01-02 15:04"),
+			e.Service, e.Date, e.Title)
+	}
+	w.Flush()
+}
+
+// execRevert sets the background to entry N of history from the
+// cache, N defaults to 1 which is the previous background. No
+// requests are made, it fails if the image is no longer cached.
+func execRevert() {
+	n := 1
+	if len(os.Args) > 2 {
+		n, err = strconv.Atoi(os.Args[2])
+		if err != nil || n < 0 {
+			fmt.Printf("Invalid entry: %q\n", os.Args[2])
+			fmt.Println("Usage: cetus revert [N]")
+			os.Exit(1)
+		}
+	}
+
+	migrateCache()
+
+	entries, err := history.Read(historyFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if n >= len(entries) {
+		log.Fatalf("history.go: entry %d doesn't exist, history has %d entries",
+			n, len(entries))
+	}
+	e := entries[n]
+
+	_, err = os.Stat(e.File)
+	if err != nil {
+		log.Fatalf("history.go: image is no longer available: %s", e.File)
+	}
+
+	// Fitted copy & overlay are derived from the original
+	// image with the current settings.
+	fitMode = conf.Get("fit.mode")
+	err = checkFitMode(fitMode, conf.Value("fit.mode").Origin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	overlayOn, err = conf.Bool("overlay.enabled")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheDir := fmt.Sprintf("%s/%s", cache.GetDir(), e.Service)
+	os.MkdirAll(cacheDir, os.ModePerm)
+
+	pic := source.Picture{Service: e.Service, Date: e.Date,
+		Title: e.Title, URL: e.URL, MediaType: "image", File: e.File}
+	imgFile := fitImage(cacheDir, e.File)
+	imgFile = overlayImage(cacheDir, imgFile, pic)
+
+	err = background.SetFromFile(imgFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	touchCache(e.File, imgFile)
+
+	// Reverting is recorded too, this way `cetus revert` switches
+	// between the last two backgrounds.
+	addHistory(time.Now(), pic, e.File, imgFile)
+	fmt.Printf("%s (%s): %s\n", e.Service, e.Date, e.Title)
+}
@@ -0,0 +1,139 @@
+// Package history records every background set by cetus so that
+// earlier backgrounds can be listed & set again.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Entry holds information about a background that was set. File is
+// the original image & Set is the fitted or captioned copy of it that
+// was set, it's empty if File was set as is. Entries added together
+// for multiple outputs have the same Time.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	Date    string    `json:"date"`
+	Title   string    `json:"title"`
+	File    string    `json:"file"`
+	Set     string    `json:"set,omitempty"`
+	URL     string    `json:"url"`
+}
+
+// Add appends e to the history file at path, it's created if it
+// doesn't exist. Every entry is stored as json on its own line.
+func Add(path string, e Entry) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"history.go: failed to open file: ", path,
+			err.Error())
+	}
+	defer f.Close()
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"history.go: failed to marshal entry",
+			err.Error())
+	}
+
+	_, err = f.Write(append(out, '\n'))
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"history.go: failed to write entry to file: ", path,
+			err.Error())
+	}
+	return err
+}
+
+// Read returns every entry in the history file at path, newest entry
+// first. History file not existing is not an error, an empty list is
+// returned. Lines that cannot be unmarshalled are skipped.
+func Read(path string) ([]Entry, error) {
+	entries := []Entry{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return entries, fmt.Errorf("%s%s\n%s",
+			"history.go: failed to open file: ", path,
+			err.Error())
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := Entry{}
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	// Entries are appended to the file so the newest one is
+	// at the end.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"history.go: failed to read file: ", path,
+			err.Error())
+	}
+	return entries, err
+}
+
+// Rewrite replaces every entry in the history file at path with the
+// entry returned by fn, order of entries is kept. Lines that cannot be
+// unmarshalled are kept as they are. The file is written to path.tmp
+// & renamed so that history is not lost if cetus is interrupted.
+func Rewrite(path string, fn func(Entry) Entry) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"history.go: failed to read file: ", path,
+			err.Error())
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	out := []byte{}
+	for _, l := range lines {
+		e := Entry{}
+		if json.Unmarshal(l, &e) == nil {
+			l, err = json.Marshal(fn(e))
+			if err != nil {
+				return fmt.Errorf("%s\n%s",
+					"history.go: failed to marshal entry",
+					err.Error())
+			}
+		}
+		out = append(append(out, l...), '\n')
+	}
+
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, out, 0644)
+	if err != nil {
+		return fmt.Errorf("%s%s\n%s",
+			"history.go: failed to write file: ", tmp,
+			err.Error())
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		err = fmt.Errorf("%s%s\n%s",
+			"history.go: failed to rename file: ", tmp,
+			err.Error())
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAddRead tests if entries added are read back newest first.
+func TestAddRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history")
+
+	entries, err := Read(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Read on missing file returned %v, %v.", entries, err)
+	}
+
+	for _, title := range []string{"first", "second"} {
+		err = Add(path, Entry{Time: time.Now(), Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Title != "second" {
+		t.Errorf("Read returned %v, want newest entry first.", entries)
+	}
+}
+
+// TestRewrite tests if entries are replaced & their order is kept.
+func TestRewrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cetus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history")
+
+	for _, file := range []string{"a", "b"} {
+		err = Add(path, Entry{Time: time.Now(), File: file})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = Rewrite(path, func(e Entry) Entry {
+		if e.File == "a" {
+			e.File = "a.jpg"
+		}
+		return e
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].File != "b" || entries[1].File != "a.jpg" {
+		t.Errorf("Read returned %v after Rewrite.", entries)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// hooks holds names of hooks that can be set by the user, they're
+// keys in hooks section of config.
+var hooks = []string{"pre_fetch", "post_fetch", "post_set"}
+
+// hookEnv returns environment variables describing pic, file is the
+// path to the image on disk.
+func hookEnv(pic source.Picture, file string) []string {
+	data, err := json.Marshal(newRecord(pic, file))
+	if err != nil {
+		data = []byte("{}")
+	}
+	return []string{
+		"CETUS_SERVICE=" + pic.Service,
+		"CETUS_DATE=" + pic.Date,
+		"CETUS_TITLE=" + pic.Title,
+		"CETUS_IMAGE=" + file,
+		"CETUS_URL=" + pic.URL,
+		"CETUS_CREDIT=" + pic.Credit,
+		"CETUS_JSON=" + string(data),
+	}
+}
+
+// runHook runs hook name set by the user with env added to the
+// environment, it does nothing if the hook is not set.
+func runHook(name string, env []string) error {
+	command := conf.Get("hooks." + name)
+	if len(command) == 0 {
+		return nil
+	}
+
+	err := runShell(command, env)
+	if err != nil {
+		err = fmt.Errorf("hooks.go: %s hook failed: %q\n%s",
+			name, command, err.Error())
+	}
+	return err
+}
+
+// runShell runs command with sh, args are passed as $1, $2, ... Output
+// of the command goes to stderr so that it doesn't mix with output of
+// cetus.
+func runShell(command string, env []string, args ...string) error {
+	cmd := exec.Command("sh", append([]string{"-c", command, "sh"}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// hooksSet returns true if the user has set any command that is run
+// by cetus.
+func hooksSet() bool {
+	for _, h := range hooks {
+		if len(conf.Get("hooks."+h)) != 0 {
+			return true
+		}
+	}
+	return len(conf.Get("palette.hook")) != 0
+}
+
+// unveilHooks adds directories in $PATH to paths if any hook is set
+// because the programs run by hooks can be anywhere.
+func unveilHooks(paths map[string]string) {
+	if !hooksSet() {
+		return
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		paths[dir] = "rx"
+	}
+}
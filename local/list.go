@@ -0,0 +1,61 @@
+package local
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// exts holds the extensions of files that are considered images.
+var exts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".bmp":  true,
+	".webp": true,
+}
+
+// List walks dir recursively & returns a sorted list of images in it,
+// files are filtered by their extension. It returns an error if dir
+// doesn't contain any image.
+func List(dir string) ([]string, error) {
+	list := []string{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() &&
+			exts[strings.ToLower(filepath.Ext(path))] {
+			list = append(list, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return list, fmt.Errorf("%s%s\n%s",
+			"list.go: failed to walk dir: ", dir,
+			err.Error())
+	}
+
+	if len(list) == 0 {
+		return list, fmt.Errorf("list.go: no image found in %s", dir)
+	}
+
+	sort.Strings(list)
+	return list, nil
+}
+
+// Next returns the image that comes after last in list, list must be
+// sorted. If last is not in list then the image that would've been
+// after it is returned, this way removing files from dir doesn't
+// reset the rotation.
+func Next(list []string, last string) string {
+	idx := sort.SearchStrings(list, last)
+	if idx < len(list) && list[idx] == last {
+		idx++
+	}
+	return list[idx%len(list)]
+}
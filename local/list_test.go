@@ -0,0 +1,26 @@
+package local
+
+import "testing"
+
+// TestNext tests if Next rotates through the list & handles images
+// that were removed.
+func TestNext(t *testing.T) {
+	list := []string{"/a.jpg", "/b.jpg", "/d.jpg"}
+
+	tests := []struct {
+		last string
+		want string
+	}{
+		{"", "/a.jpg"},
+		{"/a.jpg", "/b.jpg"},
+		{"/c.jpg", "/d.jpg"},
+		{"/d.jpg", "/a.jpg"},
+		{"/e.jpg", "/a.jpg"},
+	}
+	for _, test := range tests {
+		got := Next(list, test.last)
+		if got != test.want {
+			t.Errorf("Next(%q) = %q, want %q.", test.last, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// Local holds the image chosen from the directory, it's returned as
+// body by Fetch.
+type Local struct {
+	Path string `json:"path"`
+}
+
+// Source implements source.Source for images stored in a local
+// directory. Images are chosen sequentially, path of the last image
+// is saved in State.
+type Source struct {
+	Dir   string
+	State string
+}
+
+// Flags adds the dir flag, it defaults to s.Dir.
+func (s *Source) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&s.Dir, "dir", s.Dir, "Directory to choose images from")
+}
+
+// Paths returns the paths that local needs to read from.
+func (s *Source) Paths() map[string]string {
+	paths := make(map[string]string)
+	if len(s.Dir) != 0 {
+		paths[s.Dir] = "r"
+	}
+	return paths
+}
+
+// Local marks Source as local, images are read from s.Dir.
+func (s *Source) Local() {}
+
+// Date returns an empty string because the image is not known until
+// it's chosen. It returns an error if date was passed.
+func (s *Source) Date(q source.Query) (string, error) {
+	if len(q.Date) != 0 {
+		return "", fmt.Errorf("source.go: local doesn't support date flag")
+	}
+	return "", nil
+}
+
+// Fetch chooses an image from s.Dir & returns it as body. A random
+// image is chosen if q.Random is true, otherwise the image after the
+// last one is chosen.
+func (s *Source) Fetch(q source.Query) (string, error) {
+	if len(s.Dir) == 0 {
+		return "", fmt.Errorf("source.go: directory not set, pass -dir or set local.dir")
+	}
+
+	// Background is set from absolute path.
+	dir, err := filepath.Abs(s.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	list, err := List(dir)
+	if err != nil {
+		return "", err
+	}
+
+	res := Local{}
+	if q.Random {
+		res.Path = list[rand.Intn(len(list))]
+	} else {
+		// Not being able to read the state file is not an
+		// error, it won't exist on first run.
+		last, _ := ioutil.ReadFile(s.State)
+		res.Path = Next(list, strings.TrimSpace(string(last)))
+
+		err = ioutil.WriteFile(s.State, []byte(res.Path+"\n"), 0644)
+		if err != nil {
+			return "", fmt.Errorf("%s%s\n%s",
+				"source.go: failed to write state to file: ", s.State,
+				err.Error())
+		}
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("%s\n%s",
+			"source.go: failed to marshal res",
+			err.Error())
+	}
+	return string(out), nil
+}
+
+// Parse converts body to source.Picture, date of the picture is the
+// modification date of the file. Body is not cached.
+func (s *Source) Parse(body string) (source.Picture, error) {
+	pic := source.Picture{}
+
+	res := Local{}
+	err := json.Unmarshal([]byte(body), &res)
+	if err != nil {
+		return pic, fmt.Errorf("source.go: unmarshalling json failed\n%s",
+			err.Error())
+	}
+
+	info, err := os.Stat(res.Path)
+	if err != nil {
+		return pic, err
+	}
+
+	name := filepath.Base(res.Path)
+
+	pic.Service = "local"
+	pic.Date = info.ModTime().Format("2006-01-02")
+	pic.Title = strings.TrimSuffix(name, filepath.Ext(name))
+	pic.MediaType = "image"
+	pic.URL = fmt.Sprintf("%s%s", "file://", res.Path)
+	pic.File = res.Path
+
+	return pic, nil
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 
+	"tildegit.org/andinus/cetus/background"
 	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/config"
+	"tildegit.org/andinus/cetus/fav"
+	"tildegit.org/andinus/cetus/request"
+	"tildegit.org/andinus/cetus/source"
 	"tildegit.org/andinus/lynx"
 )
 
@@ -15,17 +21,16 @@ var (
 	notify  bool
 	print   bool
 
-	err     error
-	body    string
-	file    string
-	reqInfo map[string]string
+	err  error
+	date string
 
-	apodDate string
+	// confErr is the error returned while loading the config.
+	confErr error
+
+	conf *config.Config
 )
 
 func main() {
-	initCetus()
-
 	// Early Check: If command was not passed then print usage and
 	// exit. Later command & service both are checked, this check
 	// is for version command. If not checked then running cetus
@@ -36,27 +41,78 @@ func main() {
 		os.Exit(0)
 	}
 
+	// help & version are handled before loading the config so
+	// that they work even if it's broken.
+	switch os.Args[1] {
+	case "version", "-version", "--version", "-v":
+		fmt.Printf("Cetus %s\n", version)
+		os.Exit(0)
+
+	case "help", "-help", "--help", "-h":
+		// If help was passed then the program shouldn't exit
+		// with non-zero error code.
+		printUsage()
+		os.Exit(0)
+	}
+
+	initCetus()
 	parseArgs()
 }
 
 func initCetus() {
-	// We cannot use complex switches here so instead we unveil
-	// everything we need. This is bad but the other way will make
-	// the code too complex, we need a better structure for code.
-	// This also runs UnveilBlock, instead we could remove this
-	// unveil func & inline Unveil calls in other functions, this
-	// way we will only unveil when required.
-	//
-	// This method is still used because in earlier lynx version
-	// we had to manage build flags manually, so keeping
-	// everything in a single func made sense.
-	unveil()
+	// config command reports the error itself so that the user
+	// can inspect the config.
+	conf, confErr = config.Load(config.File(), settings)
+	if confErr != nil {
+		if os.Args[1] == "config" {
+			return
+		}
+		log.Fatal(confErr)
+	}
+
+	request.Timeout, err = conf.Duration("cetus.timeout")
+	if err != nil {
+		log.Fatal(err)
+	}
+	request.Attempts, err = conf.Int("cetus.retries")
+	if err != nil {
+		log.Fatal(err)
+	}
+	background.Backend = conf.Get("cetus.backend")
+	background.GnomeMode = conf.Get("gnome.mode")
+	background.GnomeLockScreen, err = conf.Bool("gnome.lock_screen")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	registerServices()
 }
 
+// unveil unveils everything we need. This is bad but the other way
+// will make the code too complex, we need a better structure for
+// code. This also runs UnveilBlock, instead we could remove this
+// unveil func & inline Unveil calls in other functions, this way we
+// will only unveil when required.
+//
+// This method is still used because in earlier lynx version we had
+// to manage build flags manually, so keeping everything in a single
+// func made sense. It's called after the flags are parsed because
+// sources like local need access to paths passed by the user.
 func unveil() {
 	paths := make(map[string]string)
 
+	// Sources that read from the filesystem tell us which paths
+	// they need.
+	for _, s := range source.Services() {
+		if u, ok := s.Source.(source.Unveiler); ok {
+			for path, perm := range u.Paths() {
+				paths[path] = perm
+			}
+		}
+	}
+
 	paths[cache.Dir()] = "rwc"
+	paths[fav.Dir()] = "rwc"
 	paths["/dev/null"] = "rw" // required by feh
 	paths["/etc/resolv.conf"] = "r"
 
@@ -68,12 +124,37 @@ func unveil() {
 	paths["/etc/hosts"] = "r"
 	paths["/etc/ssl"] = "r"
 
+	// Font used to draw the caption.
+	if font := conf.Get("overlay.font"); len(font) != 0 {
+		paths[font] = "r"
+	}
+
+	unveilHooks(paths)
+
+	// X11 socket & authority file, used by x11 backend.
+	paths["/tmp/.X11-unix"] = "rw"
+	if xauth := os.Getenv("XAUTHORITY"); len(xauth) != 0 {
+		paths[xauth] = "r"
+	} else {
+		paths[os.Getenv("HOME")+"/.Xauthority"] = "r"
+	}
+
+	// Sway ipc socket & swaybg pid file.
+	if sock := os.Getenv("SWAYSOCK"); len(sock) != 0 {
+		paths[sock] = "rw"
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); len(dir) != 0 {
+		paths[dir] = "rwc"
+	}
+
 	err := lynx.UnveilPaths(paths)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	commands := []string{"feh", "gsettings", "pcmanfm", "notify-send"}
+	commands := []string{"feh", "xwallpaper", "gsettings", "pcmanfm",
+		"xfconf-query", "swaybg", "dbus-send", "notify-send", "xrandr",
+		"wlr-randr", "ps", "sh"}
 
 	err = lynx.UnveilCommands(commands)
 	if err != nil {
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/history"
+	"tildegit.org/andinus/cetus/source"
+)
+
+// migrateCache renames images cached by earlier versions of cetus,
+// they were named after the title of picture. Cached json files are
+// parsed to find the pictures & their images are moved to the path
+// returned by imageBase with an extension, history is updated to
+// point to the new files. It's run only once, a marker file is created
+// in cache directory after the migration.
+func migrateCache() {
+	marker := fmt.Sprintf("%s/%s", cache.GetDir(), ".names-v2")
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+
+	moved := make(map[string]string)
+	for _, s := range source.Services() {
+		cacheDir := fmt.Sprintf("%s/%s", cache.GetDir(), s.Name)
+		files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			pic, err := s.Source.Parse(string(data))
+			if err != nil || pic.MediaType != "image" ||
+				len(pic.File) != 0 || len(pic.Title) == 0 {
+				continue
+			}
+			if len(pic.Date) == 0 {
+				pic.Date = strings.TrimSuffix(filepath.Base(file), ".json")
+			}
+
+			// Titles with "/" were never downloaded, they
+			// point to a file in a subdirectory.
+			old := fmt.Sprintf("%s/%s", cacheDir, pic.Title)
+			if filepath.Dir(old) != filepath.Clean(cacheDir) {
+				continue
+			}
+			info, err := os.Stat(old)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			ext, err := background.Ext(old, pic.URL)
+			if err != nil {
+				log.Printf("migrate.go: removing invalid cached image: %s\n", old)
+				os.Remove(old)
+				continue
+			}
+			imgFile := imageBase(pic, cacheDir) + ext
+			err = os.Rename(old, imgFile)
+			if err != nil {
+				log.Printf("migrate.go: failed to rename file: %s\n%s\n",
+					old, err.Error())
+				continue
+			}
+			moved[old] = imgFile
+		}
+	}
+
+	if len(moved) != 0 {
+		err := history.Rewrite(historyFile(), func(e history.Entry) history.Entry {
+			if file, ok := moved[e.File]; ok {
+				e.File = file
+			}
+			return e
+		})
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
+
+	os.MkdirAll(cache.GetDir(), os.ModePerm)
+	err := ioutil.WriteFile(marker, []byte{}, 0644)
+	if err != nil {
+		log.Printf("migrate.go: failed to create file: %s\n%s\n",
+			marker, err.Error())
+	}
+}
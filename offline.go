@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// offline is set by -offline flag, pictures are chosen from the cache
+// & no requests are made.
+var offline bool
+
+// cachedPicture holds a picture found in cache along with its body.
+type cachedPicture struct {
+	date string
+	body string
+}
+
+// offlineBody returns the date & cached body of a picture from service
+// s whose image is also cached. Picture of the date passed by the user
+// is returned, a random one if random flag was passed & the newest one
+// otherwise. An error is returned if no such picture is cached.
+func offlineBody(s source.Service, cacheDir string) (string, string, error) {
+	pics := cachedPictures(s, cacheDir)
+	if len(pics) == 0 {
+		return "", "", fmt.Errorf("offline.go: no %s pictures with images in cache %s",
+			s.Name, cacheDir)
+	}
+
+	switch {
+	case len(date) != 0:
+		for _, p := range pics {
+			if p.date == date {
+				return p.date, p.body, nil
+			}
+		}
+		dates := []string{}
+		for _, p := range pics {
+			dates = append(dates, p.date)
+		}
+		return "", "", fmt.Errorf("offline.go: %s picture of %s is not in cache, cached dates: %s",
+			s.Name, date, strings.Join(dates, ", "))
+
+	case random:
+		p := pics[rand.Intn(len(pics))]
+		return p.date, p.body, nil
+	}
+
+	p := pics[len(pics)-1]
+	return p.date, p.body, nil
+}
+
+// cachedPictures returns the pictures of service s whose body & image
+// are both in cacheDir, sorted by date.
+func cachedPictures(s source.Service, cacheDir string) []cachedPicture {
+	pics := []cachedPicture{}
+
+	files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		pic, err := s.Source.Parse(string(data))
+		if err != nil {
+			continue
+		}
+		if len(pic.Date) == 0 {
+			pic.Date = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		if len(cachedImage(pic, cacheDir)) == 0 {
+			continue
+		}
+		pics = append(pics, cachedPicture{pic.Date, string(data)})
+	}
+
+	sort.Slice(pics, func(i, j int) bool {
+		return pics[i].date < pics[j].date
+	})
+	return pics
+}
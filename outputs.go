@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/fit"
+	"tildegit.org/andinus/cetus/source"
+)
+
+var (
+	// span sets a single picture spanning all outputs.
+	span bool
+
+	// perOutput sets a different picture on every output.
+	perOutput bool
+)
+
+// execSet sets the background from services. A single service sets
+// the same picture on every output unless span or perOutput is set,
+// multiple services are assigned to outputs from left to right.
+func execSet(services []source.Service) error {
+	switch {
+	case span:
+		return execSpan(services[0])
+	case perOutput || len(services) > 1:
+		return execOutputs(services)
+	}
+	return execService(services[0], true)
+}
+
+// execOutputs sets a different picture on every output, services are
+// cycled if there are more outputs than services & extra services are
+// ignored with a warning. Backends that can't
+// set a background per output get a single image composed from all
+// the pictures.
+func execOutputs(services []source.Service) error {
+	outputs, err := background.Outputs()
+	if err != nil {
+		return err
+	}
+	if len(services) > len(outputs) {
+		log.Printf("outputs.go: %d services passed but only %d outputs found, ignoring %s\n",
+			len(services), len(outputs), serviceNames(services[len(outputs):]))
+	}
+
+	// srcs are the images before fitting, they're recorded in
+	// history. Palette is written for every picture as it's
+	// downloaded so the one on the last output is left.
+	pics := []source.Picture{}
+	srcs := []string{}
+	files := []string{}
+	for i, o := range outputs {
+		s := services[i%len(services)]
+
+		pic, cacheDir, err := getPicture(s)
+		if err != nil {
+			return err
+		}
+		pic, imgFile, err := imageOrCached(s, pic, cacheDir)
+		if err != nil {
+			return err
+		}
+		err = report(pic, imgFile)
+		if err != nil {
+			return err
+		}
+		if len(imgFile) == 0 {
+			return fmt.Errorf("outputs.go: %s: picture for output %s is not an image: %q",
+				s.Name, o.Name, pic.MediaType)
+		}
+
+		src := imgFile
+		imgFile = fitImageTo(cacheDir, imgFile, o.Width, o.Height, fitMode)
+		imgFile = overlayImage(cacheDir, imgFile, pic)
+
+		pics = append(pics, pic)
+		srcs = append(srcs, src)
+		files = append(files, imgFile)
+	}
+
+	canOutputs, canSpan := background.OutputSupport()
+	if !canOutputs && canSpan {
+		file := composedFile(outputs, files)
+		err = composeOutputs(file, outputs, files)
+		if err != nil {
+			return err
+		}
+		err = background.SetSpanned(file)
+		if err == nil {
+			removeComposed(file)
+		}
+	} else {
+		err = background.SetOutputs(outputs, files)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range pics {
+		addHistory(now, pics[i], srcs[i], files[i])
+	}
+	for i := range pics {
+		postSet(pics[i], files[i], "CETUS_OUTPUT="+outputs[i].Name)
+	}
+	pruneCache(append(srcs, files...)...)
+	return nil
+}
+
+// execSpan sets a single picture from s spanning all outputs.
+// Backends that can't span the background get the picture cropped
+// for every output.
+func execSpan(s source.Service) error {
+	outputs, err := background.Outputs()
+	if err != nil {
+		return err
+	}
+
+	pic, cacheDir, err := getPicture(s)
+	if err != nil {
+		return err
+	}
+	pic, imgFile, err := imageOrCached(s, pic, cacheDir)
+	if err != nil {
+		return err
+	}
+	err = report(pic, imgFile)
+	if err != nil || len(imgFile) == 0 {
+		return err
+	}
+
+	// Caption is drawn after fitting so that it isn't cropped.
+	src := imgFile
+	files := []string{}
+	r := outputBounds(outputs)
+	canOutputs, canSpan := background.OutputSupport()
+	if !canSpan && canOutputs {
+		imgFile = fitImageTo(cacheDir, imgFile, r.Dx(), r.Dy(), spanMode())
+		imgFile = overlayImage(cacheDir, imgFile, pic)
+		files, err = cropOutputs(cacheDir, imgFile, outputs)
+		if err != nil {
+			return err
+		}
+		err = background.SetOutputs(outputs, files)
+	} else {
+		imgFile = fitImageTo(cacheDir, imgFile, r.Dx(), r.Dy(), fitMode)
+		imgFile = overlayImage(cacheDir, imgFile, pic)
+		err = background.SetSpanned(imgFile)
+	}
+	if err != nil {
+		return err
+	}
+	addHistory(time.Now(), pic, src, imgFile)
+	postSet(pic, imgFile)
+
+	pruneCache(append(files, src, imgFile)...)
+	return nil
+}
+
+// composedFile returns the path to image composed from files on
+// outputs. It's named after a hash of both because backends like GNOME
+// don't reload the background if it's set to the same path again.
+func composedFile(outputs []background.Output, files []string) string {
+	h := sha1.New()
+	for i, o := range outputs {
+		fmt.Fprintf(h, "%s %d %d %d %d %s\n",
+			o.Name, o.X, o.Y, o.Width, o.Height, files[i])
+	}
+	return filepath.Join(cache.GetDir(), fmt.Sprintf("outputs-%x.jpg", h.Sum(nil)[:4]))
+}
+
+// removeComposed removes images composed earlier except keep, they're
+// stored directly under cache directory so they're not pruned.
+func removeComposed(keep string) {
+	files, _ := filepath.Glob(filepath.Join(cache.GetDir(), "outputs*.jpg"))
+	for _, f := range files {
+		if f != keep {
+			os.Remove(f)
+		}
+	}
+}
+
+// serviceNames returns the names of services separated by commas.
+func serviceNames(services []source.Service) string {
+	names := []string{}
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// composeOutputs draws files[i] at the position of outputs[i] & saves
+// the result to dst, every file is fitted to its output.
+func composeOutputs(dst string, outputs []background.Output, files []string) error {
+	bg, err := fit.ParseColor(conf.Get("fit.color"))
+	if err != nil {
+		return err
+	}
+
+	r := outputBounds(outputs)
+	canvas := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	for i, o := range outputs {
+		img, err := fit.Load(files[i])
+		if err != nil {
+			return err
+		}
+		fitted, err := fit.Apply(img, o.Width, o.Height, spanMode(), bg)
+		if err != nil {
+			return err
+		}
+
+		at := image.Rect(o.X-r.Min.X, o.Y-r.Min.Y,
+			o.X-r.Min.X+o.Width, o.Y-r.Min.Y+o.Height)
+		draw.Draw(canvas, at, fitted, image.Point{}, draw.Src)
+	}
+	return fit.Save(dst, canvas)
+}
+
+// cropOutputs fits file to the area covered by outputs & returns the
+// paths to the part of it under every output, they're saved in
+// cacheDir.
+func cropOutputs(cacheDir, file string, outputs []background.Output) ([]string, error) {
+	bg, err := fit.ParseColor(conf.Get("fit.color"))
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := fit.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	r := outputBounds(outputs)
+	fitted, err := fit.Apply(img, r.Dx(), r.Dy(), spanMode(), bg)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, o := range outputs {
+		part := fitted.SubImage(image.Rect(o.X-r.Min.X, o.Y-r.Min.Y,
+			o.X-r.Min.X+o.Width, o.Y-r.Min.Y+o.Height))
+
+		out := filepath.Join(cacheDir, fmt.Sprintf("%s-span-%s.jpg",
+			filepath.Base(file), o.Name))
+		err = fit.Save(out, part)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, out)
+	}
+	return files, nil
+}
+
+// outputBounds returns the smallest rectangle that covers every
+// output.
+func outputBounds(outputs []background.Output) image.Rectangle {
+	r := image.Rectangle{}
+	for i, o := range outputs {
+		b := image.Rect(o.X, o.Y, o.X+o.Width, o.Y+o.Height)
+		if i == 0 {
+			r = b
+			continue
+		}
+		r = r.Union(b)
+	}
+	return r
+}
+
+// spanMode returns the mode used to fit images when they're composed
+// or cropped by cetus, images have to be fitted so fill is used if
+// the user disabled fitting.
+func spanMode() string {
+	if len(fitMode) == 0 || fitMode == "none" {
+		return "fill"
+	}
+	return fitMode
+}
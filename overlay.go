@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tildegit.org/andinus/cetus/fit"
+	"tildegit.org/andinus/cetus/overlay"
+	"tildegit.org/andinus/cetus/source"
+)
+
+// overlayOn draws a caption with info about the picture on the image
+// if true.
+var overlayOn bool
+
+// overlayImage returns the path to a copy of file with a caption of
+// pic drawn on it, the copy is saved in cacheDir & reused if it
+// exists. file is returned as is if overlay is disabled or drawing
+// fails because the background can still be set without the caption.
+// Name of the copy has a hash of the caption & its settings so that
+// backends which don't reload the same path show the new caption.
+func overlayImage(cacheDir, file string, pic source.Picture) string {
+	if !overlayOn {
+		return file
+	}
+
+	h := sha1.New()
+	for _, s := range conf.Settings() {
+		if strings.HasPrefix(s.Key, "overlay.") {
+			fmt.Fprintf(h, "%s=%s\n", s.Key, conf.Get(s.Key))
+		}
+	}
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", pic.Title, pic.Date, pic.Credit, pic.Description)
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	out := filepath.Join(cacheDir, fmt.Sprintf("%s-%x-overlay.jpg", name, h.Sum(nil)[:4]))
+	if _, err := os.Stat(out); err == nil {
+		return out
+	}
+
+	err := drawOverlay(out, file, pic)
+	if err != nil {
+		log.Println(err)
+		return file
+	}
+	return out
+}
+
+// drawOverlay draws caption of pic on the image in src & saves it to
+// dst.
+func drawOverlay(dst, src string, pic source.Picture) error {
+	o, excerpt, err := overlayOptions()
+	if err != nil {
+		return err
+	}
+
+	img, err := fit.Load(src)
+	if err != nil {
+		return err
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	err = overlay.Draw(rgba, caption(pic, excerpt), o)
+	if err != nil {
+		return err
+	}
+	return fit.Save(dst, rgba)
+}
+
+// overlayOptions returns the options set by the user along with the
+// number of characters of explanation to draw.
+func overlayOptions() (overlay.Options, int, error) {
+	o := overlay.Options{Corner: conf.Get("overlay.corner")}
+
+	var err error
+	o.Font = overlay.Builtin()
+	if font := conf.Get("overlay.font"); len(font) != 0 {
+		o.Font, err = overlay.LoadBDF(font)
+		if err != nil {
+			return o, 0, err
+		}
+	}
+
+	o.Size, err = conf.Int("overlay.size")
+	if err != nil {
+		return o, 0, err
+	}
+	o.Color, err = fit.ParseColor(conf.Get("overlay.color"))
+	if err != nil {
+		return o, 0, err
+	}
+	o.Box, err = fit.ParseColor(conf.Get("overlay.box"))
+	if err != nil {
+		return o, 0, err
+	}
+	o.Opacity, err = conf.Float("overlay.opacity")
+	if err != nil {
+		return o, 0, err
+	}
+
+	excerpt, err := conf.Int("overlay.excerpt")
+	return o, excerpt, err
+}
+
+// caption returns the paragraphs drawn on the image: title, date
+// along with credit & first n characters of the description.
+func caption(pic source.Picture, n int) []string {
+	info := pic.Date
+	if len(pic.Credit) != 0 {
+		info = fmt.Sprintf("%s - %s", pic.Date, pic.Credit)
+	}
+	return []string{pic.Title, info, excerpt(pic.Description, n)}
+}
+
+// excerpt returns s cut at the last word before n characters, "..." is
+// appended if it was cut. It returns an empty string if n is 0.
+func excerpt(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	cut := string(r[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "..."
+}
@@ -0,0 +1,144 @@
+package overlay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadBDF loads the BDF font at path.
+func LoadBDF(path string) (*Font, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s\n%s",
+			"bdf.go: failed to open file: ", path,
+			err.Error())
+	}
+	defer f.Close()
+
+	font, err := ParseBDF(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s\n%s",
+			"bdf.go: failed to parse font: ", path,
+			err.Error())
+	}
+	return font, nil
+}
+
+// ParseBDF parses a font in Glyph Bitmap Distribution Format. Only
+// the fields needed to draw horizontal text are read.
+func ParseBDF(r io.Reader) (*Font, error) {
+	font := &Font{glyphs: map[rune]glyph{}}
+	ascent, descent := 0, 0
+	boxH, boxY := 0, 0
+
+	// Fields of the character being read.
+	var (
+		enc      int
+		advance  int
+		w, h     int
+		xo, yo   int
+		rows     []uint32
+		inBitmap bool
+	)
+
+	s := bufio.NewScanner(r)
+	line := 0
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBitmap && fields[0] != "ENDCHAR" {
+			// Glyphs wider than 32 pixels are skipped.
+			if w > 32 {
+				continue
+			}
+			v, err := strconv.ParseUint(fields[0], 16, 64)
+			if err != nil || len(fields[0])*4 < w {
+				return nil, fmt.Errorf("bdf.go: line %d: invalid bitmap row: %q",
+					line, fields[0])
+			}
+			// Rows are padded to a multiple of 8 bits, the
+			// padding is dropped so bit w-1 is the leftmost
+			// pixel.
+			rows = append(rows, uint32(v>>uint(len(fields[0])*4-w)))
+			continue
+		}
+
+		nums, err := atois(fields[1:])
+		if err != nil {
+			switch fields[0] {
+			case "FONTBOUNDINGBOX", "FONT_ASCENT", "FONT_DESCENT",
+				"ENCODING", "DWIDTH", "BBX":
+				return nil, fmt.Errorf("bdf.go: line %d: invalid %s",
+					line, fields[0])
+			}
+		}
+
+		switch {
+		case fields[0] == "FONTBOUNDINGBOX" && len(nums) == 4:
+			boxH, boxY = nums[1], nums[3]
+		case fields[0] == "FONT_ASCENT" && len(nums) == 1:
+			ascent = nums[0]
+		case fields[0] == "FONT_DESCENT" && len(nums) == 1:
+			descent = nums[0]
+		case fields[0] == "STARTCHAR":
+			enc, advance, w, h, xo, yo, rows = -1, 0, 0, 0, 0, 0, nil
+		case fields[0] == "ENCODING" && len(nums) >= 1:
+			enc = nums[0]
+		case fields[0] == "DWIDTH" && len(nums) >= 1:
+			advance = nums[0]
+		case fields[0] == "BBX" && len(nums) == 4:
+			w, h, xo, yo = nums[0], nums[1], nums[2], nums[3]
+		case fields[0] == "BITMAP":
+			inBitmap = true
+		case fields[0] == "ENDCHAR":
+			inBitmap = false
+			if enc < 0 || w < 0 || w > 32 || len(rows) != h {
+				continue
+			}
+			// y is relative to the baseline, it's stored
+			// relative to top of the line.
+			font.glyphs[rune(enc)] = newGlyph(advance, xo, -(yo + h), w, rows)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	// FONT_ASCENT & FONT_DESCENT are optional, bounding box is
+	// used if they're missing.
+	if ascent+descent == 0 {
+		ascent, descent = boxH+boxY, -boxY
+	}
+	if ascent+descent <= 0 || len(font.glyphs) == 0 {
+		return nil, fmt.Errorf("bdf.go: no glyphs found")
+	}
+
+	font.Height = ascent + descent
+	font.Ascent = ascent
+	for r, g := range font.glyphs {
+		g.y += ascent
+		font.glyphs[r] = g
+	}
+	return font, nil
+}
+
+// atois converts every string in s to int.
+func atois(s []string) ([]int, error) {
+	nums := make([]int, len(s))
+	for i, v := range s {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
@@ -0,0 +1,95 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+)
+
+// Font is a bitmap font. Height is the height of a line & Ascent is
+// the distance from top of the line to the baseline.
+type Font struct {
+	Height int
+	Ascent int
+	glyphs map[rune]glyph
+}
+
+// glyph is the bitmap of a character, x & y is the offset of the
+// bitmap from the pen position at top of the line. advance is the
+// distance to the next pen position.
+type glyph struct {
+	advance int
+	x, y    int
+	bitmap  *image.Alpha
+}
+
+// replacements maps characters that are common in picture info to
+// ones that are likely to be in the font.
+var replacements = map[rune]rune{
+	'‘': '\'', '’': '\'', '“': '"', '”': '"',
+	'–': '-', '—': '-', '…': '.', '·': '.',
+	'é': 'e', 'è': 'e', 'á': 'a', 'à': 'a', 'ó': 'o', 'ö': 'o',
+	'ü': 'u', 'í': 'i', 'ñ': 'n', 'ç': 'c', '\u00a0': ' ',
+}
+
+// glyph returns the glyph for r, replacements are tried if r is not
+// in the font & then '?'.
+func (f *Font) glyph(r rune) (glyph, bool) {
+	if g, ok := f.glyphs[r]; ok {
+		return g, true
+	}
+	if g, ok := f.glyphs[replacements[r]]; ok {
+		return g, true
+	}
+	g, ok := f.glyphs['?']
+	return g, ok
+}
+
+// Width returns the width of s in pixels.
+func (f *Font) Width(s string) int {
+	w := 0
+	for _, r := range s {
+		if g, ok := f.glyph(r); ok {
+			w += g.advance
+		}
+	}
+	return w
+}
+
+// Mask returns s drawn as an alpha mask, it's as wide as s & as high
+// as a line.
+func (f *Font) Mask(s string) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, f.Width(s), f.Height))
+
+	pen := 0
+	for _, r := range s {
+		g, ok := f.glyph(r)
+		if !ok {
+			continue
+		}
+		b := g.bitmap.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				a := g.bitmap.AlphaAt(x, y)
+				if a.A != 0 {
+					mask.SetAlpha(pen+g.x+x, g.y+y, a)
+				}
+			}
+		}
+		pen += g.advance
+	}
+	return mask
+}
+
+// newGlyph returns a glyph from rows of a bitmap, bit w-1 of every row
+// is the leftmost pixel.
+func newGlyph(advance, x, y, w int, rows []uint32) glyph {
+	bitmap := image.NewAlpha(image.Rect(0, 0, w, len(rows)))
+	for j, row := range rows {
+		for i := 0; i < w; i++ {
+			if row&(1<<uint(w-1-i)) != 0 {
+				bitmap.SetAlpha(i, j, color.Alpha{255})
+			}
+		}
+	}
+	return glyph{advance: advance, x: x, y: y, bitmap: bitmap}
+}
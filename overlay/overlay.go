@@ -0,0 +1,160 @@
+// Package overlay draws a caption with information about the picture
+// onto the image, it's drawn in a translucent box at a corner.
+package overlay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// Corners holds valid values of Options.Corner.
+var Corners = []string{"top-left", "top-right", "bottom-left", "bottom-right"}
+
+// Options decide how the caption is drawn. Size is the height of a
+// line in pixels, it's chosen from height of the image if it's 0. The
+// font is scaled by whole numbers so the size is rounded to a multiple
+// of font height. Opacity of the box is between 0 & 1.
+type Options struct {
+	Font    *Font
+	Corner  string
+	Size    int
+	Color   color.Color
+	Box     color.Color
+	Opacity float64
+}
+
+// Draw draws paragraphs in a box at a corner of img. Every paragraph
+// is wrapped to fit in 2/5th of the image width, empty paragraphs are
+// skipped.
+func Draw(img *image.RGBA, paragraphs []string, o Options) error {
+	if !validCorner(o.Corner) {
+		return fmt.Errorf("overlay.go: invalid corner: %q, valid corners: %s",
+			o.Corner, strings.Join(Corners, ", "))
+	}
+	if o.Opacity < 0 || o.Opacity > 1 {
+		return fmt.Errorf("overlay.go: invalid opacity: %v, must be between 0 & 1",
+			o.Opacity)
+	}
+
+	f := o.Font
+	b := img.Bounds()
+
+	size := o.Size
+	if size <= 0 {
+		size = b.Dy() / 45
+	}
+	scale := (size + f.Height/2) / f.Height
+	if scale < 1 {
+		scale = 1
+	}
+
+	// Layout is done in font pixels & then scaled.
+	maxW := b.Dx() * 2 / 5 / scale
+	lines := [][]string{}
+	textW, textH := 0, 0
+	for _, p := range paragraphs {
+		wrapped := Wrap(f, p, maxW)
+		if len(wrapped) == 0 {
+			continue
+		}
+		if len(lines) != 0 {
+			textH += f.Height / 2
+		}
+		for _, l := range wrapped {
+			if w := f.Width(l); w > textW {
+				textW = w
+			}
+			textH += f.Height
+		}
+		lines = append(lines, wrapped)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	pad := f.Height * scale / 2
+	boxW := textW*scale + 2*pad
+	boxH := textH*scale + 2*pad
+	margin := 2 * pad
+
+	x, y := b.Min.X+margin, b.Min.Y+margin
+	if strings.HasSuffix(o.Corner, "right") {
+		x = b.Max.X - margin - boxW
+	}
+	if strings.HasPrefix(o.Corner, "bottom") {
+		y = b.Max.Y - margin - boxH
+	}
+
+	r, g, bl, _ := o.Box.RGBA()
+	box := color.NRGBA{uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8),
+		uint8(o.Opacity * 255)}
+	draw.Draw(img, image.Rect(x, y, x+boxW, y+boxH),
+		image.NewUniform(box), image.Point{}, draw.Over)
+
+	text := image.NewUniform(o.Color)
+	pen := y + pad
+	for i, wrapped := range lines {
+		if i != 0 {
+			pen += f.Height / 2 * scale
+		}
+		for _, l := range wrapped {
+			mask := scaleMask(f.Mask(l), scale)
+			at := mask.Bounds().Add(image.Pt(x+pad, pen))
+			draw.DrawMask(img, at, text, image.Point{}, mask, image.Point{}, draw.Over)
+			pen += f.Height * scale
+		}
+	}
+	return nil
+}
+
+// Wrap splits s into lines that are at most maxW pixels wide in font
+// f. Words longer than maxW are put on their own line.
+func Wrap(f *Font, s string, maxW int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if len(line) == 0 {
+			line = word
+			continue
+		}
+		if f.Width(line+" "+word) > maxW {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	if len(line) != 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// scaleMask scales mask by n with nearest neighbour so that bitmap
+// fonts stay sharp.
+func scaleMask(mask *image.Alpha, n int) *image.Alpha {
+	if n == 1 {
+		return mask
+	}
+	b := mask.Bounds()
+	dst := image.NewAlpha(image.Rect(0, 0, b.Dx()*n, b.Dy()*n))
+	for y := 0; y < b.Dy()*n; y++ {
+		for x := 0; x < b.Dx()*n; x++ {
+			dst.SetAlpha(x, y, mask.AlphaAt(b.Min.X+x/n, b.Min.Y+y/n))
+		}
+	}
+	return dst
+}
+
+// validCorner returns true if c is in Corners.
+func validCorner(c string) bool {
+	for _, v := range Corners {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
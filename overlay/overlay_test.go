@@ -0,0 +1,67 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestWrap tests if Wrap keeps every line within the width.
+func TestWrap(t *testing.T) {
+	f := Builtin()
+	s := "The explanation is the best part of APOD and it's long enough to wrap."
+
+	lines := Wrap(f, s, 20*6)
+	if len(lines) < 2 {
+		t.Fatalf("Wrap returned %d lines, want more than 1.", len(lines))
+	}
+	for _, l := range lines {
+		if f.Width(l) > 20*6 {
+			t.Errorf("Line %q is %d pixels wide, want at most %d.",
+				l, f.Width(l), 20*6)
+		}
+	}
+	if strings.Join(lines, " ") != s {
+		t.Errorf("Wrapped lines don't join back to the input.")
+	}
+}
+
+// TestParseBDF tests if a glyph is parsed & placed relative to top of
+// the line.
+func TestParseBDF(t *testing.T) {
+	bdf := `STARTFONT 2.1
+FONTBOUNDINGBOX 4 6 0 -1
+STARTPROPERTIES 2
+FONT_ASCENT 5
+FONT_DESCENT 1
+ENDPROPERTIES
+CHARS 1
+STARTCHAR bar
+ENCODING 124
+DWIDTH 4 0
+BBX 1 3 1 0
+BITMAP
+80
+80
+80
+ENDCHAR
+ENDFONT
+`
+	f, err := ParseBDF(strings.NewReader(bdf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Height != 6 || f.Ascent != 5 {
+		t.Errorf("Got height %d & ascent %d, want 6 & 5.", f.Height, f.Ascent)
+	}
+
+	mask := f.Mask("|")
+	if mask.Bounds().Dx() != 4 {
+		t.Errorf("Mask is %d pixels wide, want 4.", mask.Bounds().Dx())
+	}
+	for y := 0; y < 6; y++ {
+		want := y >= 2 && y < 5
+		if got := mask.AlphaAt(1, y).A != 0; got != want {
+			t.Errorf("Pixel at (1, %d) set is %v, want %v.", y, got, want)
+		}
+	}
+}
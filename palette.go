@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/fit"
+	"tildegit.org/andinus/cetus/palette"
+)
+
+// paletteOn extracts the palette of the image after it's downloaded
+// if true.
+var paletteOn bool
+
+// writePalette extracts the palette of image & writes it to the cache
+// as json, Xresources & sh, then the hook set by the user is run.
+// Errors are not returned because the image can still be set.
+func writePalette(image string) {
+	if !paletteOn {
+		return
+	}
+
+	err := savePalette(image)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// savePalette does the work of writePalette.
+func savePalette(image string) error {
+	n, err := conf.Int("palette.colors")
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > 16 {
+		return fmt.Errorf("palette.go: palette.colors must be between 1 & 16, got %d", n)
+	}
+
+	img, err := fit.Load(image)
+	if err != nil {
+		return err
+	}
+	p := palette.New(image, palette.Extract(img, n))
+
+	data, err := p.JSON()
+	if err != nil {
+		return err
+	}
+
+	dir := cache.GetDir()
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"palette.json", data},
+		{"palette.Xresources", []byte(p.Xresources())},
+		{"palette.sh", []byte(p.Shell())},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		err = ioutil.WriteFile(path, f.data, 0644)
+		if err != nil {
+			return fmt.Errorf("%s%s\n%s",
+				"palette.go: failed to write palette to file: ", path,
+				err.Error())
+		}
+	}
+
+	// Hook is run by sh with path to json file as $1.
+	hook := conf.Get("palette.hook")
+	if len(hook) == 0 {
+		return nil
+	}
+	err = runShell(hook, nil, filepath.Join(dir, "palette.json"))
+	if err != nil {
+		err = fmt.Errorf("%s%q\n%s",
+			"palette.go: palette hook failed: ", hook,
+			err.Error())
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// Palette holds colours of an image as #rrggbb. Colors holds the
+// dominant colours, most common first. Terminal holds 16 colours
+// sorted by brightness that can be used as color0 to color15, 8 to 15
+// are brighter versions of 0 to 7.
+type Palette struct {
+	Image      string   `json:"image"`
+	Background string   `json:"background"`
+	Foreground string   `json:"foreground"`
+	Colors     []string `json:"colors"`
+	Terminal   []string `json:"terminal"`
+}
+
+// New returns the palette of image from its dominant colours.
+func New(image string, colors []color.RGBA) Palette {
+	p := Palette{Image: image, Colors: []string{}, Terminal: []string{}}
+	if len(colors) == 0 {
+		return p
+	}
+	for _, c := range colors {
+		p.Colors = append(p.Colors, hex(c))
+	}
+
+	sorted := append([]color.RGBA{}, colors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return luminance(sorted[i]) < luminance(sorted[j])
+	})
+
+	// Darkest & lightest colours are pushed towards black & white
+	// so that text is readable on the background.
+	base := make([]color.RGBA, 8)
+	for i := range base {
+		base[i] = sorted[i*len(sorted)/len(base)]
+	}
+	base[0] = mix(sorted[0], color.RGBA{0, 0, 0, 255}, 0.5)
+	base[7] = mix(sorted[len(sorted)-1], color.RGBA{255, 255, 255, 255}, 0.5)
+
+	for _, c := range base {
+		p.Terminal = append(p.Terminal, hex(c))
+	}
+	for _, c := range base {
+		p.Terminal = append(p.Terminal, hex(mix(c, color.RGBA{255, 255, 255, 255}, 0.25)))
+	}
+
+	p.Background = p.Terminal[0]
+	p.Foreground = p.Terminal[7]
+	return p
+}
+
+// JSON returns p as indented json.
+func (p Palette) JSON() ([]byte, error) {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"format.go: failed to marshal palette",
+			err.Error())
+	}
+	return append(out, '\n'), err
+}
+
+// Xresources returns p in the format read by xrdb.
+func (p Palette) Xresources() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "*background: %s\n", p.Background)
+	fmt.Fprintf(b, "*foreground: %s\n", p.Foreground)
+	fmt.Fprintf(b, "*cursorColor: %s\n", p.Foreground)
+	for i, c := range p.Terminal {
+		fmt.Fprintf(b, "*color%d: %s\n", i, c)
+	}
+	return b.String()
+}
+
+// Shell returns p as variables that can be sourced by sh.
+func (p Palette) Shell() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "wallpaper='%s'\n", strings.Replace(p.Image, "'", `'\''`, -1))
+	fmt.Fprintf(b, "background='%s'\n", p.Background)
+	fmt.Fprintf(b, "foreground='%s'\n", p.Foreground)
+	for i, c := range p.Terminal {
+		fmt.Fprintf(b, "color%d='%s'\n", i, c)
+	}
+	return b.String()
+}
+
+// hex returns c as #rrggbb.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// luminance returns the relative luminance of c.
+func luminance(c color.RGBA) float64 {
+	return 0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)
+}
+
+// mix returns c blended with to, f is the fraction of to.
+func mix(c, to color.RGBA, f float64) color.RGBA {
+	m := func(a, b uint8) uint8 {
+		return uint8(float64(a)*(1-f) + float64(b)*f + 0.5)
+	}
+	return color.RGBA{m(c.R, to.R), m(c.G, to.G), m(c.B, to.B), 255}
+}
@@ -0,0 +1,143 @@
+// Package palette extracts dominant colours of an image & formats them
+// so that other programs can use them, like terminals & status bars.
+package palette
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// maxSamples is the maximum number of pixels considered, larger images
+// are sampled at regular intervals.
+const maxSamples = 1 << 16
+
+// Extract returns up to n dominant colours of img, most common first.
+// Colours are found with median cut: pixels are split into boxes near
+// the median of the channel with the largest range until there are n
+// boxes, the colour of a box is the average of its pixels.
+func Extract(img image.Image, n int) []color.RGBA {
+	boxes := [][]color.RGBA{sample(img)}
+	if len(boxes[0]) == 0 || n <= 0 {
+		return nil
+	}
+
+	for len(boxes) < n {
+		// Box with the largest range is split, ties are broken
+		// by number of pixels.
+		i, best, count := -1, 0, 0
+		for j, b := range boxes {
+			_, r := widest(b)
+			if r > best || (r == best && r > 0 && len(b) > count) {
+				i, best, count = j, r, len(b)
+			}
+		}
+		if i < 0 {
+			break
+		}
+
+		b := boxes[i]
+		ch, _ := widest(b)
+		sort.Slice(b, func(x, y int) bool {
+			return channel(b[x], ch) < channel(b[y], ch)
+		})
+		mid := split(b, ch)
+		boxes[i] = b[:mid]
+		boxes = append(boxes, b[mid:])
+	}
+
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return len(boxes[i]) > len(boxes[j])
+	})
+
+	colors := []color.RGBA{}
+	for _, b := range boxes {
+		colors = append(colors, average(b))
+	}
+	return colors
+}
+
+// split returns the index at which sorted pixels are split. It's the
+// median moved so that pixels with the same value of ch stay in the
+// same box.
+func split(pixels []color.RGBA, ch int) int {
+	mid := len(pixels) / 2
+	v := channel(pixels[mid-1], ch)
+	for mid < len(pixels) && channel(pixels[mid], ch) == v {
+		mid++
+	}
+	if mid == len(pixels) {
+		for mid > 0 && channel(pixels[mid-1], ch) == v {
+			mid--
+		}
+	}
+	return mid
+}
+
+// sample returns at most maxSamples pixels of img.
+func sample(img image.Image) []color.RGBA {
+	b := img.Bounds()
+	step := 1
+	for (b.Dx()/step)*(b.Dy()/step) > maxSamples {
+		step++
+	}
+
+	pixels := []color.RGBA{}
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			pixels = append(pixels,
+				color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8), 255})
+		}
+	}
+	return pixels
+}
+
+// widest returns the channel (0 red, 1 green, 2 blue) with the
+// largest range in pixels along with the range.
+func widest(pixels []color.RGBA) (int, int) {
+	if len(pixels) < 2 {
+		return 0, 0
+	}
+
+	ch, best := 0, -1
+	for c := 0; c < 3; c++ {
+		min, max := 255, 0
+		for _, p := range pixels {
+			v := channel(p, c)
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if max-min > best {
+			ch, best = c, max-min
+		}
+	}
+	return ch, best
+}
+
+// channel returns channel c (0 red, 1 green, 2 blue) of p.
+func channel(p color.RGBA, c int) int {
+	switch c {
+	case 0:
+		return int(p.R)
+	case 1:
+		return int(p.G)
+	}
+	return int(p.B)
+}
+
+// average returns the average colour of pixels.
+func average(pixels []color.RGBA) color.RGBA {
+	var r, g, b int
+	for _, p := range pixels {
+		r += int(p.R)
+		g += int(p.G)
+		b += int(p.B)
+	}
+	n := len(pixels)
+	return color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}
+}
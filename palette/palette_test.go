@@ -0,0 +1,38 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestExtract tests if the most common colour comes first. Three
+// quarters of the source is red & the rest is blue.
+func TestExtract(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			c := color.RGBA{200, 10, 10, 255}
+			if x >= 75 {
+				c = color.RGBA{10, 10, 200, 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	colors := Extract(src, 4)
+	if len(colors) != 2 {
+		t.Fatalf("Got %d colours, want 2.", len(colors))
+	}
+	if colors[0] != (color.RGBA{200, 10, 10, 255}) {
+		t.Errorf("First colour is %v, want red.", colors[0])
+	}
+	if colors[1] != (color.RGBA{10, 10, 200, 255}) {
+		t.Errorf("Second colour is %v, want blue.", colors[1])
+	}
+
+	p := New("a.jpg", colors)
+	if len(p.Terminal) != 16 {
+		t.Errorf("Got %d terminal colours, want 16.", len(p.Terminal))
+	}
+}
@@ -3,9 +3,14 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
 	"math/rand"
 	"os"
+	"strings"
 	"time"
+
+	"tildegit.org/andinus/cetus/background"
+	"tildegit.org/andinus/cetus/source"
 )
 
 // parseArgs will be parsing the arguments, it will verify if they are
@@ -15,17 +20,19 @@ func parseArgs() {
 	// of os.Args was not checked beforehand because there would
 	// be no os.Args[1].
 	switch os.Args[1] {
-	case "version", "-version", "--version", "-v":
-		fmt.Printf("Cetus %s\n", version)
-		os.Exit(0)
+	case "config", "history", "revert", "fav", "backends", "cache":
+		// These commands parse their own arguments.
 
-	case "help", "-help", "--help", "-h":
-		// If help was passed then the program shouldn't exit
-		// with non-zero error code.
-		printUsage()
-		os.Exit(0)
+	case "set", "fetch", "daemon":
+		// Service can be omitted if the user has set a default
+		// service, it's inserted after the command.
+		def := conf.Get("cetus.service")
+		if len(def) != 0 &&
+			(len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "-")) {
+			os.Args = append(os.Args[:2],
+				append([]string{def}, os.Args[2:]...)...)
+		}
 
-	case "set", "fetch":
 		// If command & service was not passed then print
 		// usage and exit.
 		if len(os.Args) < 3 {
@@ -41,53 +48,158 @@ func parseArgs() {
 
 	rand.Seed(time.Now().Unix())
 
-	// If the program has reached this far then that means a valid
-	// command was passed & now we should check if a valid service
-	// was passed and parse the flags.
+	var services []source.Service
+	switch os.Args[1] {
+	case "set", "fetch", "daemon":
+		services = parseFlags()
+	}
+
+	// unveil is called after the flags are parsed because sources
+	// like local need access to paths passed by the user, every
+	// command runs after it.
+	unveil()
+
+	switch os.Args[1] {
+	case "config":
+		execConfig()
+	case "history":
+		execHistory()
+	case "revert":
+		execRevert()
+	case "fav":
+		execFav()
+	case "backends":
+		execBackends()
+	case "cache":
+		execCache()
+
+	case "daemon":
+		migrateCache()
+		execDaemon(services)
+	case "fetch":
+		migrateCache()
+		for _, s := range services {
+			err = execService(s, false)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	default:
+		migrateCache()
+		err = execSet(services)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// parseFlags parses the flags of set, fetch & daemon commands. If the
+// program has reached this far then that means a valid command was
+// passed & now we should check if a valid service was passed and
+// parse the flags. Services passed by the user are returned.
+func parseFlags() []source.Service {
 	cetus := flag.NewFlagSet("cetus", flag.ExitOnError)
 
-	// We first declare common flags then service specific flags.
+	// Default values of boolean flags are taken from config.
+	defNotify, err := conf.Bool("cetus.notify")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defPrint, err := conf.Bool("cetus.print")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defOverlay, err := conf.Bool("overlay.enabled")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defPalette, err := conf.Bool("palette.enabled")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Flags are common for all services, sources return an error
+	// if they don't support a flag.
 	cetus.BoolVar(&dump, "dump", false, "Dump the response")
-	cetus.BoolVar(&notify, "notify", false, "Send a desktop notification with info")
-	cetus.BoolVar(&print, "print", false, "Print information")
+	cetus.BoolVar(&notify, "notify", defNotify, "Send a desktop notification with info")
+	cetus.BoolVar(&print, "print", defPrint, "Print information")
+	cetus.BoolVar(&jsonOut, "json", false, "Print information as json")
+	cetus.StringVar(&format, "format", "",
+		"Print information with a Go template, e.g. '{{.Title}} {{.File}}'")
 	cetus.BoolVar(&random, "random", false, "Choose a random image")
+	cetus.StringVar(&date, "date", "", "Date of picture to retrieve (YYYY-MM-DD)")
+	cetus.BoolVar(&offline, "offline", false, "Choose picture from cache, don't make requests")
+	cetus.StringVar(&background.Backend, "backend", background.Backend,
+		"Program used to set the background, run `cetus backends` to list them")
+	cetus.StringVar(&fitMode, "fit", conf.Get("fit.mode"),
+		"Fit image to screen (none, fill, fit, center, smart)")
+	cetus.BoolVar(&overlayOn, "overlay", defOverlay,
+		"Draw a caption with picture info on the image")
+	cetus.BoolVar(&paletteOn, "palette", defPalette,
+		"Extract colour palette of the image after it's downloaded")
 
-	switch os.Args[2] {
-	case "apod", "nasa":
-		defDate := time.Now().UTC().
-			// Subtract 8 hours from UTC to ensure program
-			// doesn't fail. This still doesn't mean
-			// anything, I've emailed them asking about
-			// timezone on server but no response :(
-			//
-			// The server returns "400 Bad Request" when
-			// you request future date, so if I request
-			// 2020-04-25 on 2020-04-24 23:59 UTC it will
-			// return "400 Bad Request" but if I
-			// re-request it on 2020-04-25 00:04 UTC it
-			// returns "500 Internal Server Error", I
-			// think the API server runs on UTC but the
-			// program that is responsible for syncing the
-			// images is running on a different timezone,
-			// which is why it returns "500 Internal
-			// Server Error" instead of "400 Bad Request".
-			//
-			// Hopefully this should work, it will work if
-			// the program responsible for syncing images
-			// is in or before UTC-8.
-			Add(time.Duration(-8) * time.Hour).
-			Format("2006-01-02")
-
-		cetus.StringVar(&apodDate, "date", defDate, "Date of NASA APOD to retrieve")
-		cetus.Parse(os.Args[3:])
-
-		execAPOD()
-	case "bpod", "bing":
-		cetus.Parse(os.Args[3:])
-		execBPOD()
-	default:
-		fmt.Printf("Invalid service: %q\n", os.Args[2])
+	if os.Args[1] == "daemon" {
+		cetus.StringVar(&daemonAt, "at", "", "Set background daily at this local time (HH:MM)")
+		cetus.DurationVar(&daemonEvery, "every", 0, "Set background every interval (e.g. 30m)")
+	}
+
+	if os.Args[1] != "fetch" {
+		cetus.BoolVar(&span, "span", false, "Set a single picture spanning all outputs")
+		cetus.BoolVar(&perOutput, "per-output", false, "Set a different picture on every output")
+	}
+
+	// Multiple services can be passed separated by commas, they're
+	// assigned to outputs from left to right.
+	services := []source.Service{}
+	for _, name := range strings.Split(os.Args[2], ",") {
+		s, err := source.Get(name)
+		if err != nil {
+			fmt.Printf("Invalid service: %q\n", name)
+			printUsage()
+			os.Exit(1)
+		}
+
+		// Service specific flags, they're added once even if
+		// the service is repeated.
+		if f, ok := s.Source.(source.Flagger); ok && !hasService(services, s.Name) {
+			f.Flags(cetus)
+		}
+		services = append(services, s)
+	}
+	cetus.Parse(os.Args[3:])
+
+	err = parseFormat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fit mode is checked here so that an invalid mode isn't
+	// found only after the image is downloaded.
+	origin := conf.Value("fit.mode").Origin
+	cetus.Visit(func(f *flag.Flag) {
+		if f.Name == "fit" {
+			origin = "-fit"
+		}
+	})
+	err = checkFitMode(fitMode, origin)
+	if err != nil {
+		fmt.Println(err)
 		printUsage()
 		os.Exit(1)
 	}
+
+	if span && (perOutput || len(services) > 1) {
+		log.Fatal("parseargs.go: -span takes a single service & can't be used with -per-output")
+	}
+	return services
+}
+
+// hasService returns true if a service named name is in services.
+func hasService(services []source.Service, name string) bool {
+	for _, s := range services {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
 }
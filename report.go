@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+var (
+	// jsonOut prints info about the picture as json if true.
+	jsonOut bool
+
+	// format is the template used to print info about the picture,
+	// it's parsed into formatTmpl.
+	format     string
+	formatTmpl *template.Template
+)
+
+// record holds information about a picture, it's printed with -json &
+// -format & passed to hooks as json. File is the path to the image on
+// disk.
+type record struct {
+	Service     string `json:"service"`
+	Date        string `json:"date"`
+	Title       string `json:"title"`
+	Credit      string `json:"credit,omitempty"`
+	CreditURL   string `json:"credit_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	MediaType   string `json:"media_type"`
+	URL         string `json:"url"`
+	File        string `json:"file,omitempty"`
+}
+
+// newRecord returns record of pic, file is the path to the image on
+// disk.
+func newRecord(pic source.Picture, file string) record {
+	return record{
+		Service:     pic.Service,
+		Date:        pic.Date,
+		Title:       pic.Title,
+		Credit:      pic.Credit,
+		CreditURL:   pic.CreditURL,
+		Description: pic.Description,
+		MediaType:   pic.MediaType,
+		URL:         pic.URL,
+		File:        file,
+	}
+}
+
+// parseFormat parses the template passed to -format, it's done before
+// anything is fetched so that errors are reported early.
+func parseFormat() error {
+	if len(format) == 0 {
+		return nil
+	}
+
+	var err error
+	formatTmpl, err = template.New("format").Parse(format)
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"report.go: failed to parse -format template",
+			err.Error())
+	}
+	return err
+}
+
+// report prints info about pic with the template passed to -format,
+// as json or as text depending on the flags. file is the path to the
+// image on disk, it's empty if the image is not on disk.
+func report(pic source.Picture, file string) error {
+	r := newRecord(pic, file)
+
+	switch {
+	case formatTmpl != nil:
+		err := formatTmpl.Execute(os.Stdout, r)
+		if err != nil {
+			return fmt.Errorf("%s\n%s",
+				"report.go: failed to execute -format template",
+				err.Error())
+		}
+		fmt.Println()
+
+	case jsonOut:
+		out, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("%s\n%s",
+				"report.go: failed to marshal record",
+				err.Error())
+		}
+		fmt.Println(string(out))
+
+	case print:
+		printPicture(pic)
+	}
+	return nil
+}
@@ -8,31 +8,20 @@ import (
 	"time"
 )
 
+// Timeout is the time limit for every attempt of requests made by Do,
+// it can be changed by the user.
+var Timeout = time.Second * 64
+
 // GetRes takes api and params as input and returns the body and
-// error.
+// error. Requests are retried on temporary failures, see Do.
 func GetRes(api string, params map[string]string) (string, error) {
 	var body string
 
-	c := http.Client{
-		// TODO: timeout should be configurable by the user
-		Timeout: time.Second * 64,
-	}
-
-	req, err := http.NewRequest(http.MethodGet, api, nil)
+	req, err := NewRequest(api)
 	if err != nil {
-		err = fmt.Errorf("%s\n%s",
-			"request.go: failed to create request",
-			err.Error())
 		return body, err
 	}
 
-	// User-Agent should be passed with every request to make work
-	// easier for the server handler. Include contact information
-	// along with the project name so they could reach you if
-	// required.
-	req.Header.Set("User-Agent",
-		"Andinus / Cetus - https://andinus.nand.sh/projects/cetus")
-
 	// Params is a simple map[string]string which contains
 	// parameters that needs to be passed along with the request.
 	// There is no check involved here & it should be done before
@@ -43,7 +32,7 @@ func GetRes(api string, params map[string]string) (string, error) {
 	}
 	req.URL.RawQuery = q.Encode()
 
-	res, err := c.Do(req)
+	res, err := Do(req)
 	if err != nil {
 		err = fmt.Errorf("%s\n%s",
 			"request.go: failed to get response",
@@ -0,0 +1,140 @@
+package request
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// Attempts is the maximum number of times a request is tried,
+	// it can be changed by the user.
+	Attempts = 4
+
+	// Backoff is the delay before the first retry, it's doubled
+	// after every retry up to MaxBackoff.
+	Backoff    = 2 * time.Second
+	MaxBackoff = time.Minute
+)
+
+// Unreachable is set to true when a request fails because the server
+// couldn't be reached, cetus falls back to the cache then. It's never
+// reset by this package.
+var Unreachable bool
+
+// sleep is replaced in tests.
+var sleep = time.Sleep
+
+// NewRequest returns a GET request for url with User-Agent set.
+func NewRequest(url string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s",
+			"retry.go: failed to create request",
+			err.Error())
+	}
+
+	// User-Agent should be passed with every request to make work
+	// easier for the server handler. Include contact information
+	// along with the project name so they could reach you if
+	// required.
+	req.Header.Set("User-Agent",
+		"Andinus / Cetus - https://andinus.nand.sh/projects/cetus")
+	return req, nil
+}
+
+// Do sends req & retries on network errors & status codes that are
+// likely to be temporary. Every attempt is limited by Timeout, that
+// includes reading the body. Delay between attempts grows
+// exponentially with jitter, Retry-After is used if the server sent
+// it. Response of the last attempt is returned if all of them fail
+// with a temporary status, the caller must close its body.
+func Do(req *http.Request) (*http.Response, error) {
+	c := http.Client{
+		Timeout: Timeout,
+	}
+
+	backoff := Backoff
+	for attempt := 1; ; attempt++ {
+		res, err := c.Do(req)
+		if err == nil && !retryable(res.StatusCode) {
+			return res, nil
+		}
+		if attempt >= Attempts {
+			if err != nil {
+				Unreachable = true
+				return nil, fmt.Errorf("%s%d%s\n%s",
+					"retry.go: failed to get response after ", attempt, " attempts",
+					err.Error())
+			}
+			return res, nil
+		}
+
+		delay := jitter(backoff)
+		if err == nil {
+			if d, ok := retryAfter(res); ok {
+				delay = d
+			}
+			res.Body.Close()
+		}
+		sleep(delay)
+
+		backoff *= 2
+		if backoff > MaxBackoff {
+			backoff = MaxBackoff
+		}
+	}
+}
+
+// retryable returns true if status code is likely to be temporary.
+func retryable(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay asked by the server in Retry-After
+// header of 429 & 503 responses, it's capped at MaxBackoff. The header
+// holds either seconds or a date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res.StatusCode != http.StatusTooManyRequests &&
+		res.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := res.Header.Get("Retry-After")
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	} else {
+		return 0, false
+	}
+
+	if d < 0 {
+		d = 0
+	}
+	if d > MaxBackoff {
+		d = MaxBackoff
+	}
+	return d, true
+}
+
+// jitter returns a random duration between half of d & d so that
+// clients retrying together don't hit the server at once.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
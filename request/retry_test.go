@@ -0,0 +1,46 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestDo tests if temporary failures are retried & Retry-After is
+// used as the delay.
+func TestDo(t *testing.T) {
+	delays := []time.Duration{}
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { sleep = time.Sleep }()
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer ts.Close()
+
+	body, err := GetRes(ts.URL, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "ok" || calls != 3 {
+		t.Errorf("Got body %q after %d calls, want \"ok\" after 3.", body, calls)
+	}
+	if len(delays) != 2 || delays[0] != 7*time.Second {
+		t.Errorf("Got delays %v, want 7s & then backoff.", delays)
+	}
+	if delays[1] < Backoff || delays[1] > 2*Backoff {
+		t.Errorf("Second delay is %s, want between %s & %s.",
+			delays[1], Backoff, 2*Backoff)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"tildegit.org/andinus/cetus/apod"
+	"tildegit.org/andinus/cetus/bpod"
+	"tildegit.org/andinus/cetus/cache"
+	"tildegit.org/andinus/cetus/fav"
+	"tildegit.org/andinus/cetus/local"
+	"tildegit.org/andinus/cetus/source"
+	"tildegit.org/andinus/cetus/wpod"
+)
+
+// registerServices registers every service supported by cetus. New
+// sources only have to implement source.Source & be registered here,
+// the pipeline in exec.go handles the rest.
+func registerServices() {
+	source.Register(source.Service{
+		Name:    "apod",
+		Desc:    "NASA Astronomy Picture of the Day",
+		Aliases: []string{"nasa"},
+		Source: &apod.Source{
+			API: conf.Get("apod.api"),
+			Key: conf.Get("apod.key"),
+		},
+	})
+
+	source.Register(source.Service{
+		Name:    "bpod",
+		Desc:    "Bing Photo of the Day",
+		Aliases: []string{"bing"},
+		Source: &bpod.Source{
+			API:   conf.Get("bpod.api"),
+			Cache: fmt.Sprintf("%s/%s", cache.GetDir(), "bpod"),
+		},
+	})
+
+	source.Register(source.Service{
+		Name:    "wpod",
+		Desc:    "Wikimedia Commons Picture of the Day",
+		Aliases: []string{"wikimedia"},
+		Source: &wpod.Source{
+			API: conf.Get("wpod.api"),
+		},
+	})
+
+	source.Register(source.Service{
+		Name: "local",
+		Desc: "Images from a local directory",
+		Source: &local.Source{
+			Dir:   conf.Get("local.dir"),
+			State: fmt.Sprintf("%s/%s", cache.GetDir(), "local.state"),
+		},
+	})
+
+	source.Register(source.Service{
+		Name: "fav",
+		Desc: "Favorite backgrounds",
+		Source: &fav.Source{
+			State: fmt.Sprintf("%s/%s", cache.GetDir(), "fav.state"),
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tildegit.org/andinus/cetus/config"
+)
+
+// settings holds every configuration key known to cetus. Values are
+// taken from flags, environment variables, config file & these
+// defaults in that order.
+var settings = []config.Setting{
+	{Key: "cetus.service", Env: "CETUS_DEFAULT_SERVICE", Default: "",
+		Desc: "Service used when it's not passed"},
+	{Key: "cetus.timeout", Env: "CETUS_TIMEOUT", Default: "64s",
+		Desc: "Timeout for every attempt of a request"},
+	{Key: "cetus.retries", Env: "CETUS_RETRIES", Default: "4",
+		Desc: "Maximum number of attempts of a request"},
+	{Key: "cetus.notify", Env: "CETUS_NOTIFY", Default: "false",
+		Desc: "Send a desktop notification by default"},
+	{Key: "cetus.print", Env: "CETUS_PRINT", Default: "false",
+		Desc: "Print information by default"},
+	{Key: "cetus.backend", Env: "CETUS_BACKEND", Default: "auto",
+		Desc: "Program used to set the background"},
+
+	{Key: "fit.mode", Env: "CETUS_FIT_MODE", Default: "none",
+		Desc: "Fit image to screen before setting (none, fill, fit, center, smart)"},
+	{Key: "fit.color", Env: "CETUS_FIT_COLOR", Default: "#000000",
+		Desc: "Colour of area not covered by the image (fit & center)"},
+	{Key: "fit.resolution", Env: "CETUS_FIT_RESOLUTION", Default: "",
+		Desc: "Screen resolution (WxH), detected if empty"},
+
+	{Key: "overlay.enabled", Env: "CETUS_OVERLAY", Default: "false",
+		Desc: "Draw a caption with picture info on the image by default"},
+	{Key: "overlay.corner", Env: "CETUS_OVERLAY_CORNER", Default: "bottom-right",
+		Desc: "Corner of the caption (top-left, top-right, bottom-left, bottom-right)"},
+	{Key: "overlay.font", Env: "CETUS_OVERLAY_FONT", Default: "",
+		Desc: "BDF font file, built-in font is used if empty"},
+	{Key: "overlay.size", Env: "CETUS_OVERLAY_SIZE", Default: "0",
+		Desc: "Height of a line in pixels, 0 to choose from image height"},
+	{Key: "overlay.color", Env: "CETUS_OVERLAY_COLOR", Default: "#ffffff",
+		Desc: "Colour of the text"},
+	{Key: "overlay.box", Env: "CETUS_OVERLAY_BOX", Default: "#000000",
+		Desc: "Colour of the box behind the text"},
+	{Key: "overlay.opacity", Env: "CETUS_OVERLAY_OPACITY", Default: "0.6",
+		Desc: "Opacity of the box (0 to 1)"},
+	{Key: "overlay.excerpt", Env: "CETUS_OVERLAY_EXCERPT", Default: "0",
+		Desc: "Characters of the explanation to draw, 0 to disable"},
+
+	{Key: "palette.enabled", Env: "CETUS_PALETTE", Default: "false",
+		Desc: "Extract colour palette of the background by default"},
+	{Key: "palette.colors", Env: "CETUS_PALETTE_COLORS", Default: "8",
+		Desc: "Number of dominant colours to extract (1 to 16)"},
+	{Key: "palette.hook", Env: "CETUS_PALETTE_HOOK", Default: "",
+		Desc: "Command run by sh after palette is written, $1 is the json file"},
+
+	{Key: "hooks.pre_fetch", Env: "CETUS_HOOK_PRE_FETCH", Default: "",
+		Desc: "Command run by sh before the picture is fetched"},
+	{Key: "hooks.post_fetch", Env: "CETUS_HOOK_POST_FETCH", Default: "",
+		Desc: "Command run by sh after the picture is fetched"},
+	{Key: "hooks.post_set", Env: "CETUS_HOOK_POST_SET", Default: "",
+		Desc: "Command run by sh after the background is set"},
+
+	{Key: "gnome.mode", Env: "CETUS_GNOME_MODE", Default: "zoom",
+		Desc: "GNOME picture-options (zoom, scaled, spanned, centered, ...)"},
+	{Key: "gnome.lock_screen", Env: "CETUS_GNOME_LOCK_SCREEN", Default: "false",
+		Desc: "Set GNOME lock screen background too"},
+
+	{Key: "cache.max_age", Env: "CETUS_CACHE_MAX_AGE", Default: "0",
+		Desc: "Remove cached files older than this, 0 to disable"},
+	{Key: "cache.max_size", Env: "CETUS_CACHE_MAX_SIZE", Default: "0",
+		Desc: "Maximum size of cache (K, M, G suffix), 0 to disable"},
+
+	{Key: "apod.api", Env: "APOD_API", Default: "https://api.nasa.gov/planetary/apod",
+		Desc: "NASA APOD api endpoint"},
+	{Key: "apod.key", Env: "APOD_KEY", Default: "DEMO_KEY",
+		Desc: "NASA APOD api key"},
+	{Key: "bpod.api", Env: "BPOD_API", Default: "https://www.bing.com/HPImageArchive.aspx",
+		Desc: "Bing api endpoint"},
+	{Key: "wpod.api", Env: "WPOD_API", Default: "https://api.wikimedia.org/feed/v1/wikipedia/en/featured",
+		Desc: "Wikimedia featured feed endpoint"},
+	{Key: "local.dir", Env: "CETUS_LOCAL_DIR", Default: "",
+		Desc: "Directory to choose local images from"},
+}
+
+// execConfig handles config command, currently only show is
+// supported which prints the effective value of every setting & where
+// it came from.
+func execConfig() {
+	if len(os.Args) < 3 || os.Args[2] != "show" {
+		fmt.Println("Usage: cetus config show")
+		os.Exit(1)
+	}
+
+	found := "found"
+	if !conf.Found {
+		found = "not found"
+	}
+	fmt.Printf("Config file: %s (%s)\n\n", conf.File, found)
+
+	for _, s := range conf.Settings() {
+		v := conf.Value(s.Key)
+		fmt.Printf("%-17s = %q (%s)\n", s.Key, v.Value, v.Origin)
+	}
+
+	if confErr != nil {
+		fmt.Printf("\nError: %s\n", confErr)
+		os.Exit(1)
+	}
+}
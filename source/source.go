@@ -0,0 +1,134 @@
+// Package source defines the interface that every picture source
+// (apod, bpod, ...) implements & a registry that holds them. The rest
+// of cetus only talks to sources through this package, adding a new
+// source doesn't require changes to the pipeline.
+package source
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+// Picture holds normalized information about a picture. Every source
+// converts its response to a Picture so that cache, notification,
+// print & set logic doesn't have to know about the format of every
+// api.
+type Picture struct {
+	Service     string
+	Date        string
+	Title       string
+	Credit      string
+	CreditURL   string
+	Description string
+	MediaType   string
+	URL         string
+
+	// File is the path to the image if it's already on disk,
+	// sources that read pictures from the filesystem set it &
+	// the image is not downloaded.
+	File string
+
+	// Body is what gets written to the cache, Parse must be able
+	// to parse it again. This is not always the same as the
+	// response body, bpod for example returns a list of pictures
+	// when random flag is passed.
+	Body string
+}
+
+// Query holds the parameters passed by the user.
+type Query struct {
+	Date   string
+	Random bool
+}
+
+// Source is implemented by every service that provides pictures.
+type Source interface {
+	// Date returns the date of the picture that will be fetched
+	// for q. It must return an empty string if the date cannot
+	// be known without making a request, cache is not used in
+	// that case. Random dates should be picked here.
+	Date(q Query) (string, error)
+
+	// Fetch returns the response body for q, q.Date will be the
+	// value returned by Date.
+	Fetch(q Query) (string, error)
+
+	// Parse converts body returned by Fetch (or read from the
+	// cache) to a Picture. Picture.Date may be left empty if the
+	// body doesn't contain it, the date returned by Date is used
+	// in that case.
+	Parse(body string) (Picture, error)
+}
+
+// Flagger is implemented by sources that accept flags other than the
+// common flags.
+type Flagger interface {
+	Flags(fs *flag.FlagSet)
+}
+
+// Unveiler is implemented by sources that need access to the
+// filesystem. Paths returns a map of path & permissions, it's used to
+// unveil paths on OpenBSD.
+type Unveiler interface {
+	Paths() map[string]string
+}
+
+// Local is implemented by sources that read pictures from the
+// filesystem instead of making requests, they're used as usual in
+// offline mode. Local is only a marker, it does nothing.
+type Local interface {
+	Local()
+}
+
+// Service holds a Source along with the information required to
+// present it to the user.
+type Service struct {
+	Name    string
+	Desc    string
+	Aliases []string
+	Source  Source
+}
+
+var (
+	services = make(map[string]Service)
+	names    []string
+)
+
+// Register makes a service available by its name & aliases. It panics
+// if Register is called twice with the same name or alias, this
+// behaviour is similar to database/sql.Register.
+func Register(s Service) {
+	if s.Source == nil {
+		panic("source: Register source is nil")
+	}
+	for _, n := range append([]string{s.Name}, s.Aliases...) {
+		if _, dup := services[n]; dup {
+			panic("source: Register called twice for " + n)
+		}
+		services[n] = s
+	}
+	names = append(names, s.Name)
+}
+
+// Get returns the service registered with name or alias, it returns an
+// error if the service doesn't exist.
+func Get(name string) (Service, error) {
+	s, exists := services[name]
+	if !exists {
+		return s, fmt.Errorf("source.go: invalid service: %q", name)
+	}
+	return s, nil
+}
+
+// Services returns all registered services sorted by name, aliases are
+// not included.
+func Services() []Service {
+	sort.Strings(names)
+
+	list := []Service{}
+	for _, n := range names {
+		list = append(list, services[n])
+	}
+	return list
+}
@@ -1,15 +1,27 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"tildegit.org/andinus/cetus/source"
+)
 
 func printUsage() {
-	fmt.Println("Usage: cetus <command> <service> [<flags>]")
+	fmt.Println("Usage: cetus <command> <service>[,<service>...] [<flags>]")
 	fmt.Println("\nCommands: ")
-	fmt.Println(" set     Set the background")
-	fmt.Println(" fetch   Fetch the response only")
-	fmt.Println(" help    Print help")
-	fmt.Println(" version Print Cetus version")
+	fmt.Println(" set      Set the background")
+	fmt.Println(" fetch    Fetch the response only")
+	fmt.Println(" daemon   Set the background on a schedule")
+	fmt.Println(" history  List backgrounds that were set")
+	fmt.Println(" revert   Set background N from history (default 1)")
+	fmt.Println(" fav      Manage favorites (fav add [N], fav list)")
+	fmt.Println(" backends List backends used to set the background")
+	fmt.Println(" cache    Manage the cache (list, stats, verify, prune, clear)")
+	fmt.Println(" config   Show configuration (config show)")
+	fmt.Println(" help     Print help")
+	fmt.Println(" version  Print Cetus version")
 	fmt.Println("\nServices: ")
-	fmt.Println(" apod   NASA Astronomy Picture of the Day")
-	fmt.Println(" bpod   Bing Photo of the Day")
+	for _, s := range source.Services() {
+		fmt.Printf(" %-6s %s\n", s.Name, s.Desc)
+	}
 }
@@ -0,0 +1,72 @@
+package wpod
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"tildegit.org/andinus/cetus/request"
+)
+
+// WPOD holds the response from the api. The feed returns a lot more
+// than the featured image, we only unmarshal the image.
+type WPOD struct {
+	Image Image `json:"image"`
+}
+
+// Image holds information about the featured image.
+type Image struct {
+	Title    string `json:"title"`
+	FilePage string `json:"file_page"`
+
+	Image struct {
+		Source string `json:"source"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	} `json:"image"`
+
+	Artist struct {
+		Text string `json:"text"`
+	} `json:"artist"`
+
+	License struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"license"`
+
+	Description struct {
+		Text string `json:"text"`
+	} `json:"description"`
+}
+
+// UnmarshalJson will take body as input & unmarshal it to res.
+func UnmarshalJson(res *WPOD, body string) error {
+	err := json.Unmarshal([]byte(body), res)
+	if err != nil {
+		err = fmt.Errorf("json.go: unmarshalling json failed\n%s",
+			err.Error())
+	}
+	return err
+}
+
+// GetJson takes reqInfo as input and returns the body and an error.
+func GetJson(reqInfo map[string]string) (string, error) {
+	var body string
+	var err error
+
+	re := regexp.MustCompile("^((19|20)\\d\\d)-(0?[1-9]|1[012])-(0?[1-9]|[12][0-9]|3[01])$")
+	if !re.MatchString(reqInfo["date"]) {
+		err = fmt.Errorf("json.go: %s does not match format 'YYYY-MM-DD'",
+			reqInfo["date"])
+		return body, err
+	}
+
+	// The feed takes date as a part of the path instead of a
+	// parameter, 2020-04-25 is requested as api/2020/04/25.
+	api := fmt.Sprintf("%s/%s", strings.TrimSuffix(reqInfo["api"], "/"),
+		strings.Replace(reqInfo["date"], "-", "/", -1))
+
+	body, err = request.GetRes(api, map[string]string{})
+	return body, err
+}
@@ -0,0 +1,16 @@
+package wpod
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RandDate returns a random date between 2004-11-01 & today. Wikimedia
+// Commons Picture of the Day started in November 2004.
+func RandDate() string {
+	min := time.Date(2004, 11, 1, 0, 0, 0, 0, time.UTC).Unix()
+	max := time.Now().UTC().Unix()
+
+	sec := rand.Int63n(max-min) + min
+	return time.Unix(sec, 0).UTC().Format("2006-01-02")
+}
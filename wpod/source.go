@@ -0,0 +1,81 @@
+package wpod
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tildegit.org/andinus/cetus/source"
+)
+
+// Source implements source.Source for Wikimedia Commons Picture of
+// the Day.
+type Source struct {
+	API string
+}
+
+// Date returns the date of WPOD that should be fetched for q. If random
+// is true then a random date is returned, if date was not passed then
+// today's date (UTC) is returned.
+func (s *Source) Date(q source.Query) (string, error) {
+	if q.Random {
+		return RandDate(), nil
+	}
+	if len(q.Date) != 0 {
+		return q.Date, nil
+	}
+	return time.Now().UTC().Format("2006-01-02"), nil
+}
+
+// Fetch returns the response body of the featured feed for q.Date.
+func (s *Source) Fetch(q source.Query) (string, error) {
+	reqInfo := make(map[string]string)
+	reqInfo["api"] = s.API
+	reqInfo["date"] = q.Date
+
+	body, err := GetJson(reqInfo)
+	if err != nil {
+		err = fmt.Errorf("%s\n%s",
+			"source.go: failed to get json response from api",
+			err.Error())
+	}
+	return body, err
+}
+
+// Parse converts body to source.Picture. The feed doesn't include the
+// date of the featured image so Date is left empty, it's filled from
+// the query.
+func (s *Source) Parse(body string) (source.Picture, error) {
+	pic := source.Picture{}
+
+	res := WPOD{}
+	err := UnmarshalJson(&res, body)
+	if err != nil {
+		return pic, err
+	}
+
+	// Feed for some dates doesn't contain an image.
+	if len(res.Image.Image.Source) == 0 {
+		return pic, fmt.Errorf("source.go: response doesn't contain featured image")
+	}
+
+	// Title is the file name, "File:Example.jpg" is converted to
+	// "Example".
+	title := strings.TrimPrefix(res.Image.Title, "File:")
+	title = strings.TrimSuffix(title, filepath.Ext(title))
+
+	pic.Service = "wpod"
+	pic.Title = title
+	pic.Credit = res.Image.Artist.Text
+	if len(res.Image.License.Type) != 0 {
+		pic.Credit = fmt.Sprintf("%s (%s)", pic.Credit, res.Image.License.Type)
+	}
+	pic.CreditURL = res.Image.FilePage
+	pic.Description = res.Image.Description.Text
+	pic.MediaType = "image"
+	pic.URL = res.Image.Image.Source
+	pic.Body = body
+
+	return pic, nil
+}
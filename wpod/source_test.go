@@ -0,0 +1,36 @@
+package wpod
+
+import (
+	"testing"
+)
+
+// TestParse tests if Parse converts the featured feed to a picture
+// correctly. body only contains the fields that we use.
+func TestParse(t *testing.T) {
+	body := `{"image": {"title": "File:Example image.jpg",
+"file_page": "https://commons.wikimedia.org/wiki/File:Example_image.jpg",
+"image": {"source": "https://upload.wikimedia.org/example.jpg"},
+"artist": {"text": "Someone"},
+"license": {"type": "CC BY-SA 4.0"},
+"description": {"text": "An example."}}}`
+
+	s := Source{}
+	pic, err := s.Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pic.Title != "Example image" {
+		t.Errorf("Title is incorrect, got %q, want %q.", pic.Title, "Example image")
+	}
+	if pic.Credit != "Someone (CC BY-SA 4.0)" {
+		t.Errorf("Credit is incorrect, got %q, want %q.", pic.Credit, "Someone (CC BY-SA 4.0)")
+	}
+	if pic.URL != "https://upload.wikimedia.org/example.jpg" {
+		t.Errorf("URL is incorrect, got %q.", pic.URL)
+	}
+
+	_, err = s.Parse(`{"tfa": {}}`)
+	if err == nil {
+		t.Errorf("Parse didn't return an error for response without image.")
+	}
+}
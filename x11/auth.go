@@ -0,0 +1,71 @@
+package x11
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Families of addresses in Xauthority file.
+const (
+	familyLocal = 256
+	familyWild  = 65535
+)
+
+// readAuth returns the name & data of authorization for display from
+// Xauthority file. Only MIT-MAGIC-COOKIE-1 is supported, empty name
+// & data are returned if an entry is not found. Connection is tried
+// without authorization in that case.
+func readAuth(display string) (string, []byte) {
+	file := os.Getenv("XAUTHORITY")
+	if len(file) == 0 {
+		file = os.Getenv("HOME") + "/.Xauthority"
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", nil
+	}
+	defer f.Close()
+
+	hostname, _ := os.Hostname()
+	r := bufio.NewReader(f)
+	for {
+		var family uint16
+		err = binary.Read(r, binary.BigEndian, &family)
+		if err != nil {
+			return "", nil
+		}
+
+		// Every field is prefixed with its length.
+		fields := make([][]byte, 4)
+		for i := range fields {
+			fields[i], err = readField(r)
+			if err != nil {
+				return "", nil
+			}
+		}
+		addr, num, name, data := string(fields[0]), string(fields[1]),
+			string(fields[2]), fields[3]
+
+		if name != "MIT-MAGIC-COOKIE-1" || (len(num) != 0 && num != display) {
+			continue
+		}
+		if family == familyWild || (family == familyLocal && addr == hostname) {
+			return name, data
+		}
+	}
+}
+
+// readField reads a field prefixed with its length from r.
+func readField(r io.Reader) ([]byte, error) {
+	var n uint16
+	err := binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return nil, err
+	}
+	field := make([]byte, n)
+	_, err = io.ReadFull(r, field)
+	return field, err
+}
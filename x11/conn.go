@@ -0,0 +1,340 @@
+// Package x11 implements the parts of X11 protocol required to set
+// the root window background. It doesn't depend on Xlib or any
+// program, this way cetus can set the background on minimal X11
+// setups where feh is not installed.
+package x11
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// order is the byte order used by the connection, it is sent to the
+// server during setup.
+var order = binary.LittleEndian
+
+// Screen holds information about a screen that is required to draw on
+// the root window.
+type Screen struct {
+	Root   uint32
+	Width  int
+	Height int
+	Depth  uint8
+	Visual Visual
+
+	// BitsPerPixel & ScanlinePad describe the format of images
+	// of Depth.
+	BitsPerPixel int
+	ScanlinePad  int
+}
+
+// Visual holds the masks of color channels in a pixel.
+type Visual struct {
+	ID        uint32
+	RedMask   uint32
+	GreenMask uint32
+	BlueMask  uint32
+}
+
+// Conn is a connection to the X server.
+type Conn struct {
+	conn   net.Conn
+	Screen Screen
+
+	// MSBFirst is true if the server expects images in big
+	// endian byte order.
+	MSBFirst bool
+
+	idBase   uint32
+	idMask   uint32
+	idNext   uint32
+	maxReq   int
+	sequence uint16
+
+	// ignore holds sequence numbers of requests whose errors are
+	// not returned.
+	ignore map[uint16]bool
+}
+
+// Connect connects to the display in DISPLAY environment variable.
+func Connect() (*Conn, error) {
+	display := os.Getenv("DISPLAY")
+	if len(display) == 0 {
+		return nil, fmt.Errorf("conn.go: DISPLAY is not set")
+	}
+
+	host, num, screen, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	if len(host) == 0 || host == "unix" {
+		conn, err = net.Dial("unix", "/tmp/.X11-unix/X"+num)
+	} else {
+		n, _ := strconv.Atoi(num)
+		conn, err = net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(6000+n)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s%s\n%s",
+			"conn.go: failed to connect to display: ", display,
+			err.Error())
+	}
+
+	c := &Conn{conn: conn}
+	err = c.setup(num, screen)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// parseDisplay parses DISPLAY of the form [host]:num[.screen].
+func parseDisplay(display string) (string, string, int, error) {
+	idx := strings.LastIndex(display, ":")
+	if idx == -1 {
+		return "", "", 0, fmt.Errorf("conn.go: invalid DISPLAY: %q", display)
+	}
+	host, num := display[:idx], display[idx+1:]
+
+	screen := 0
+	if dot := strings.Index(num, "."); dot != -1 {
+		var err error
+		screen, err = strconv.Atoi(num[dot+1:])
+		if err != nil {
+			return "", "", 0, fmt.Errorf("conn.go: invalid DISPLAY: %q", display)
+		}
+		num = num[:dot]
+	}
+	if _, err := strconv.Atoi(num); err != nil {
+		return "", "", 0, fmt.Errorf("conn.go: invalid DISPLAY: %q", display)
+	}
+	return host, num, screen, nil
+}
+
+// pad returns the number of bytes required to pad n to a multiple of
+// 4.
+func pad(n int) int {
+	return (4 - n%4) % 4
+}
+
+// setup sends the connection setup request & reads information about
+// screen from the reply.
+func (c *Conn) setup(display string, screen int) error {
+	name, data := readAuth(display)
+
+	req := new(bytes.Buffer)
+	req.WriteByte('l') // little endian
+	req.WriteByte(0)
+	binary.Write(req, order, uint16(11)) // protocol major version
+	binary.Write(req, order, uint16(0))  // protocol minor version
+	binary.Write(req, order, uint16(len(name)))
+	binary.Write(req, order, uint16(len(data)))
+	req.Write([]byte{0, 0})
+	req.WriteString(name)
+	req.Write(make([]byte, pad(len(name))))
+	req.Write(data)
+	req.Write(make([]byte, pad(len(data))))
+
+	_, err := c.conn.Write(req.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"conn.go: failed to send setup request",
+			err.Error())
+	}
+
+	header := make([]byte, 8)
+	_, err = io.ReadFull(c.conn, header)
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"conn.go: failed to read setup reply",
+			err.Error())
+	}
+	reply := make([]byte, int(order.Uint16(header[6:]))*4)
+	_, err = io.ReadFull(c.conn, reply)
+	if err != nil {
+		return fmt.Errorf("%s\n%s",
+			"conn.go: failed to read setup reply",
+			err.Error())
+	}
+
+	if header[0] != 1 {
+		// Reason for failure follows the header, its length
+		// is in the second byte.
+		reason := string(reply)
+		if int(header[1]) <= len(reply) {
+			reason = string(reply[:header[1]])
+		}
+		return fmt.Errorf("conn.go: connection refused by server: %s", reason)
+	}
+
+	return c.parseSetup(reply, screen)
+}
+
+// parseSetup parses the setup reply after its header.
+func (c *Conn) parseSetup(r []byte, screen int) error {
+	invalid := fmt.Errorf("conn.go: invalid setup reply")
+	if len(r) < 32 {
+		return invalid
+	}
+
+	c.idBase = order.Uint32(r[4:])
+	c.idMask = order.Uint32(r[8:])
+	vendorLen := int(order.Uint16(r[16:]))
+	c.maxReq = int(order.Uint16(r[18:])) * 4
+	numScreens := int(r[20])
+	numFormats := int(r[21])
+	c.MSBFirst = r[22] == 1
+
+	// Formats describe how images of every depth are stored.
+	type format struct{ bpp, pad int }
+	formats := make(map[uint8]format)
+
+	off := 32 + vendorLen + pad(vendorLen)
+	for i := 0; i < numFormats; i++ {
+		if off+8 > len(r) {
+			return invalid
+		}
+		formats[r[off]] = format{int(r[off+1]), int(r[off+2])}
+		off += 8
+	}
+
+	if screen >= numScreens {
+		return fmt.Errorf("conn.go: screen %d doesn't exist", screen)
+	}
+	for i := 0; i <= screen; i++ {
+		if off+40 > len(r) {
+			return invalid
+		}
+		s := Screen{
+			Root:   order.Uint32(r[off:]),
+			Width:  int(order.Uint16(r[off+20:])),
+			Height: int(order.Uint16(r[off+22:])),
+			Depth:  r[off+38],
+		}
+		visualID := order.Uint32(r[off+32:])
+		numDepths := int(r[off+39])
+		off += 40
+
+		// Every depth has a list of visuals, we only need the
+		// root visual.
+		for d := 0; d < numDepths; d++ {
+			if off+8 > len(r) {
+				return invalid
+			}
+			numVisuals := int(order.Uint16(r[off+2:]))
+			off += 8
+			for v := 0; v < numVisuals; v++ {
+				if off+24 > len(r) {
+					return invalid
+				}
+				if order.Uint32(r[off:]) == visualID {
+					s.Visual = Visual{
+						ID:        visualID,
+						RedMask:   order.Uint32(r[off+8:]),
+						GreenMask: order.Uint32(r[off+12:]),
+						BlueMask:  order.Uint32(r[off+16:]),
+					}
+				}
+				off += 24
+			}
+		}
+
+		f, exists := formats[s.Depth]
+		if !exists {
+			return fmt.Errorf("conn.go: no format for depth %d", s.Depth)
+		}
+		s.BitsPerPixel, s.ScanlinePad = f.bpp, f.pad
+		c.Screen = s
+	}
+	return nil
+}
+
+// newID returns a new resource id.
+func (c *Conn) newID() uint32 {
+	c.idNext++
+	return c.idBase | (c.idNext & c.idMask)
+}
+
+// send sends a request with opcode & data byte, body is the request
+// without the 4 byte header. It returns the sequence number of the
+// request.
+func (c *Conn) send(opcode, data byte, body []byte) (uint16, error) {
+	body = append(body, make([]byte, pad(len(body)))...)
+
+	req := make([]byte, 4, 4+len(body))
+	req[0] = opcode
+	req[1] = data
+	order.PutUint16(req[2:], uint16((4+len(body))/4))
+	req = append(req, body...)
+
+	_, err := c.conn.Write(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s%d\n%s",
+			"conn.go: failed to send request: ", opcode,
+			err.Error())
+	}
+	c.sequence++
+	return c.sequence, nil
+}
+
+// sendIgnore is like send but errors of the request are ignored by
+// reply.
+func (c *Conn) sendIgnore(opcode, data byte, body []byte) error {
+	seq, err := c.send(opcode, data, body)
+	if err != nil {
+		return err
+	}
+	if c.ignore == nil {
+		c.ignore = make(map[uint16]bool)
+	}
+	c.ignore[seq] = true
+	return nil
+}
+
+// reply reads the reply of request with sequence number seq. Events
+// & replies of earlier requests are skipped, errors are returned
+// unless the request was sent with sendIgnore.
+func (c *Conn) reply(seq uint16) ([]byte, error) {
+	for {
+		packet := make([]byte, 32)
+		_, err := io.ReadFull(c.conn, packet)
+		if err != nil {
+			return nil, fmt.Errorf("%s\n%s",
+				"conn.go: failed to read reply",
+				err.Error())
+		}
+
+		switch packet[0] {
+		case 0:
+			if c.ignore[order.Uint16(packet[2:])] {
+				continue
+			}
+			return nil, fmt.Errorf("conn.go: request %d failed with error code %d",
+				packet[10], packet[1])
+		case 1:
+			extra := make([]byte, int(order.Uint32(packet[4:]))*4)
+			_, err = io.ReadFull(c.conn, extra)
+			if err != nil {
+				return nil, fmt.Errorf("%s\n%s",
+					"conn.go: failed to read reply",
+					err.Error())
+			}
+			if order.Uint16(packet[2:]) == seq {
+				return append(packet, extra...), nil
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package x11
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// TestParseDisplay tests if parseDisplay handles host, display number
+// & screen.
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		display string
+		host    string
+		num     string
+		screen  int
+	}{
+		{":0", "", "0", 0},
+		{":1.2", "", "1", 2},
+		{"localhost:10.0", "localhost", "10", 0},
+	}
+	for _, test := range tests {
+		host, num, screen, err := parseDisplay(test.display)
+		if err != nil {
+			t.Errorf("parseDisplay(%q) returned error: %s", test.display, err)
+			continue
+		}
+		if host != test.host || num != test.num || screen != test.screen {
+			t.Errorf("parseDisplay(%q) = %q, %q, %d, want %q, %q, %d.",
+				test.display, host, num, screen, test.host, test.num, test.screen)
+		}
+	}
+
+	_, _, _, err := parseDisplay("invalid")
+	if err == nil {
+		t.Errorf("parseDisplay didn't return an error for invalid display.")
+	}
+}
+
+// TestReplyIgnore tests if reply skips errors of requests sent with
+// sendIgnore & returns errors of other requests.
+func TestReplyIgnore(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	c := &Conn{conn: client}
+
+	go func() {
+		// Read the 3 requests, each one is 4 bytes.
+		io.ReadFull(server, make([]byte, 12))
+
+		packet := func(kind byte, seq uint16) []byte {
+			p := make([]byte, 32)
+			p[0] = kind
+			order.PutUint16(p[2:], seq)
+			return p
+		}
+		server.Write(packet(0, 1))
+		server.Write(packet(1, 2))
+		server.Write(packet(0, 3))
+	}()
+
+	c.sendIgnore(opKillClient, 0, nil)
+	seq, _ := c.send(opGetInputFocus, 0, nil)
+	c.send(opGetInputFocus, 0, nil)
+
+	_, err := c.reply(seq)
+	if err != nil {
+		t.Errorf("reply returned error of ignored request: %s", err)
+	}
+	_, err = c.reply(seq + 1)
+	if err == nil {
+		t.Errorf("reply didn't return error of request.")
+	}
+}
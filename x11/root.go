@@ -0,0 +1,275 @@
+package x11
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math/bits"
+)
+
+// Opcodes of requests used to set the root window background.
+const (
+	opChangeWindowAttributes = 2
+	opInternAtom             = 16
+	opChangeProperty         = 18
+	opGetProperty            = 20
+	opGetInputFocus          = 43
+	opCreatePixmap           = 53
+	opCreateGC               = 55
+	opClearArea              = 61
+	opPutImage               = 72
+	opSetCloseDownMode       = 112
+	opKillClient             = 113
+)
+
+// atomPixmap is the predefined atom for PIXMAP type.
+const atomPixmap = 20
+
+// SetRoot draws img on a pixmap & sets it as the background of root
+// window. _XROOTPMAP_ID & ESETROOT_PMAP_ID are set to the pixmap so
+// that pseudo-transparent programs & other setters can find it, this
+// is what Esetroot & feh do. The pixmap created earlier by such a
+// program is freed. img should be the size of the screen.
+func (c *Conn) SetRoot(img *image.RGBA) error {
+	s := c.Screen
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	xroot, err := c.internAtom("_XROOTPMAP_ID")
+	if err != nil {
+		return err
+	}
+	esetroot, err := c.internAtom("ESETROOT_PMAP_ID")
+	if err != nil {
+		return err
+	}
+
+	// Free the pixmap set by the last setter, it was retained
+	// after that client exited. It's only freed if both
+	// properties point to it otherwise it may belong to a
+	// program that's still running.
+	old1, err := c.getPixmapProperty(s.Root, xroot)
+	if err != nil {
+		return err
+	}
+	old2, err := c.getPixmapProperty(s.Root, esetroot)
+	if err != nil {
+		return err
+	}
+	if old1 != 0 && old1 == old2 {
+		// The pixmap may have been freed already, errors are
+		// ignored like feh & Esetroot do.
+		body := make([]byte, 4)
+		order.PutUint32(body, old1)
+		err = c.sendIgnore(opKillClient, 0, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	pixmap := c.newID()
+	body := make([]byte, 12)
+	order.PutUint32(body, pixmap)
+	order.PutUint32(body[4:], s.Root)
+	order.PutUint16(body[8:], uint16(w))
+	order.PutUint16(body[10:], uint16(h))
+	_, err = c.send(opCreatePixmap, s.Depth, body)
+	if err != nil {
+		return err
+	}
+
+	gc := c.newID()
+	body = make([]byte, 12)
+	order.PutUint32(body, gc)
+	order.PutUint32(body[4:], pixmap)
+	_, err = c.send(opCreateGC, 0, body)
+	if err != nil {
+		return err
+	}
+
+	err = c.putImage(pixmap, gc, img)
+	if err != nil {
+		return err
+	}
+
+	for _, atom := range []uint32{xroot, esetroot} {
+		body = make([]byte, 20)
+		order.PutUint32(body, s.Root)
+		order.PutUint32(body[4:], atom)
+		order.PutUint32(body[8:], atomPixmap)
+		body[12] = 32 // format
+		order.PutUint32(body[16:], 1)
+		body = append(body, make([]byte, 4)...)
+		order.PutUint32(body[20:], pixmap)
+		_, err = c.send(opChangeProperty, 0, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Set background-pixmap of root window & clear it so that
+	// it's redrawn.
+	body = make([]byte, 12)
+	order.PutUint32(body, s.Root)
+	order.PutUint32(body[4:], 1) // CWBackPixmap
+	order.PutUint32(body[8:], pixmap)
+	_, err = c.send(opChangeWindowAttributes, 0, body)
+	if err != nil {
+		return err
+	}
+
+	body = make([]byte, 12)
+	order.PutUint32(body, s.Root)
+	_, err = c.send(opClearArea, 0, body)
+	if err != nil {
+		return err
+	}
+
+	// Pixmap is freed when the connection is closed unless close
+	// down mode is RetainPermanent.
+	_, err = c.send(opSetCloseDownMode, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	// GetInputFocus is used as a round trip, errors of earlier
+	// requests are returned before its reply.
+	seq, err := c.send(opGetInputFocus, 0, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.reply(seq)
+	return err
+}
+
+// internAtom returns the atom for name, it's created if it doesn't
+// exist.
+func (c *Conn) internAtom(name string) (uint32, error) {
+	body := make([]byte, 4)
+	order.PutUint16(body, uint16(len(name)))
+	body = append(body, name...)
+
+	seq, err := c.send(opInternAtom, 0, body)
+	if err != nil {
+		return 0, err
+	}
+	reply, err := c.reply(seq)
+	if err != nil {
+		return 0, err
+	}
+	return order.Uint32(reply[8:]), nil
+}
+
+// getPixmapProperty returns the pixmap stored in property of window,
+// it returns 0 if property doesn't exist or is not a pixmap.
+func (c *Conn) getPixmapProperty(window, property uint32) (uint32, error) {
+	body := make([]byte, 20)
+	order.PutUint32(body, window)
+	order.PutUint32(body[4:], property)
+	order.PutUint32(body[12:], 0) // long-offset
+	order.PutUint32(body[16:], 1) // long-length
+
+	seq, err := c.send(opGetProperty, 0, body)
+	if err != nil {
+		return 0, err
+	}
+	reply, err := c.reply(seq)
+	if err != nil {
+		return 0, err
+	}
+
+	if order.Uint32(reply[8:]) != atomPixmap || reply[1] != 32 ||
+		order.Uint32(reply[16:]) != 1 || len(reply) < 36 {
+		return 0, nil
+	}
+	return order.Uint32(reply[32:]), nil
+}
+
+// putImage sends img to drawable in ZPixmap format. Image is sent in
+// chunks of rows because a request can't be larger than maxReq.
+func (c *Conn) putImage(drawable, gc uint32, img *image.RGBA) error {
+	s := c.Screen
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if s.BitsPerPixel != 16 && s.BitsPerPixel != 24 && s.BitsPerPixel != 32 {
+		return fmt.Errorf("root.go: unsupported bits per pixel: %d", s.BitsPerPixel)
+	}
+	bpp := s.BitsPerPixel / 8
+
+	stride := w * bpp
+	if p := s.ScanlinePad / 8; p > 0 && stride%p != 0 {
+		stride += p - stride%p
+	}
+
+	rows := (c.maxReq - 24) / stride
+	if rows <= 0 {
+		return fmt.Errorf("root.go: screen is too wide to send a row in a request")
+	}
+
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if c.MSBFirst {
+		byteOrder = binary.BigEndian
+	}
+
+	rs, rb := maskShift(s.Visual.RedMask)
+	gs, gb := maskShift(s.Visual.GreenMask)
+	bs, bb := maskShift(s.Visual.BlueMask)
+
+	pixel := make([]byte, 4)
+	for y0 := 0; y0 < h; y0 += rows {
+		n := rows
+		if y0+n > h {
+			n = h - y0
+		}
+
+		body := make([]byte, 20, 20+stride*n)
+		order.PutUint32(body, drawable)
+		order.PutUint32(body[4:], gc)
+		order.PutUint16(body[8:], uint16(w))
+		order.PutUint16(body[10:], uint16(n))
+		order.PutUint16(body[12:], 0)
+		order.PutUint16(body[14:], uint16(y0))
+		body[16] = 0 // left-pad
+		body[17] = s.Depth
+
+		for y := y0; y < y0+n; y++ {
+			row := make([]byte, stride)
+			for x := 0; x < w; x++ {
+				off := img.PixOffset(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)
+				p := scale(img.Pix[off], rb)<<rs |
+					scale(img.Pix[off+1], gb)<<gs |
+					scale(img.Pix[off+2], bb)<<bs
+
+				byteOrder.PutUint32(pixel, p)
+				if c.MSBFirst {
+					copy(row[x*bpp:], pixel[4-bpp:])
+				} else {
+					copy(row[x*bpp:], pixel[:bpp])
+				}
+			}
+			body = append(body, row...)
+		}
+
+		_, err := c.send(opPutImage, 2, body) // ZPixmap
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maskShift returns the shift & number of bits of mask.
+func maskShift(mask uint32) (uint, uint) {
+	if mask == 0 {
+		return 0, 0
+	}
+	shift := uint(bits.TrailingZeros32(mask))
+	return shift, uint(bits.OnesCount32(mask >> shift))
+}
+
+// scale scales an 8 bit color value to n bits.
+func scale(v uint8, n uint) uint32 {
+	if n >= 8 {
+		return uint32(v) << (n - 8)
+	}
+	return uint32(v) >> (8 - n)
+}